@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go-api/config"
+	"go-api/db"
+	"go-api/migrations"
+	"go-api/notification"
+	"go-api/repository"
+	"go-api/usecase"
+)
+
+// App reúne a conexão com o banco, os repositórios e os usecases montados a partir da
+// configuração. O servidor e os comandos de administração usam a mesma montagem, de modo que
+// um usuário criado pela linha de comando passa pelas mesmas regras de um criado pela API.
+type App struct {
+	Config *config.Config
+	DB     *sql.DB
+
+	// camada de repository
+	UsuarioStore                repository.UsuarioStore
+	TarefaStore                 repository.TarefaStore
+	IdentidadeExternaRepository repository.IdentidadeExternaRepository
+
+	// camada usecase
+	TokenUseCase          *usecase.TokenUsecase
+	UsuarioUseCase        usecase.UsuarioUsecase
+	TarefaUseCase         usecase.TarefaUsecase
+	TentativaLoginUseCase *usecase.TentativaLoginUsecase
+	DoisFatoresUseCase    *usecase.DoisFatoresUsecase
+	ApiKeyUseCase         *usecase.ApiKeyUsecase
+	AuthUseCase           *usecase.AuthUsecase
+	ResetSenhaUseCase     *usecase.ResetSenhaUsecase
+}
+
+// Open conecta ao banco de cfg e monta os repositórios e os usecases. As migrations não são
+// aplicadas aqui: quem usa o App decide se chama Migrar.
+func Open(ctx context.Context, cfg *config.Config) (*App, error) {
+	keys, err := config.LoadKeySetFromConfig(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConnection, err := db.ConnectDB(ctx, cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &App{Config: cfg, DB: dbConnection}
+	a.UsuarioStore, a.TarefaStore = newStores(cfg.Database, dbConnection)
+	TokenRepository := repository.NewTokenRepository(dbConnection)
+	RefreshTokenRepository := repository.NewRefreshTokenRepository(dbConnection)
+	SessaoRepository := repository.NewSessaoRepository(dbConnection)
+	TentativaLoginRepository := repository.NewTentativaLoginRepository(dbConnection)
+	ResetSenhaRepository := repository.NewResetSenhaRepository(dbConnection)
+	DoisFatoresRepository := repository.NewDoisFatoresRepository(dbConnection)
+	ApiKeyRepository := repository.NewApiKeyRepository(dbConnection)
+	a.IdentidadeExternaRepository = repository.NewIdentidadeExternaRepository(dbConnection)
+
+	a.TokenUseCase = usecase.NewTokenUseCase(TokenRepository, RefreshTokenRepository, SessaoRepository, cfg.JWT, keys)
+	a.UsuarioUseCase = usecase.NewUsuarioUseCase(a.UsuarioStore, a.TokenUseCase, cfg.Password)
+	a.TarefaUseCase = usecase.NewTarefaUseCase(a.TarefaStore)
+	a.TentativaLoginUseCase = usecase.NewTentativaLoginUsecase(TentativaLoginRepository, cfg.LoginProtection)
+	a.DoisFatoresUseCase = usecase.NewDoisFatoresUsecase(DoisFatoresRepository, a.UsuarioStore)
+	a.ApiKeyUseCase = usecase.NewApiKeyUsecase(ApiKeyRepository, a.UsuarioStore)
+	a.AuthUseCase = usecase.NewAuthUsecase(a.UsuarioStore, a.TokenUseCase, a.TentativaLoginUseCase, a.DoisFatoresUseCase, a.ApiKeyUseCase, cfg.Password)
+	a.ResetSenhaUseCase = usecase.NewResetSenhaUsecase(a.UsuarioStore, ResetSenhaRepository, a.TokenUseCase, a.TentativaLoginUseCase, notification.NewSender(cfg.Notification.File), cfg.ResetSenha, cfg.Password)
+	return a, nil
+}
+
+// Close fecha a conexão com o banco
+func (a *App) Close() error {
+	return a.DB.Close()
+}
+
+// Migrator retorna o migrator do banco do App
+func (a *App) Migrator() (*migrations.Migrator, error) {
+	return migrations.New(a.DB, a.Config.Database.Driver)
+}
+
+// Migrar aplica as migrations pendentes quando database.auto_migrate estiver ativo e recusa
+// bancos cujo esquema seja mais novo que esta versão da API
+func (a *App) Migrar(ctx context.Context) error {
+	migrator, err := a.Migrator()
+	if err != nil {
+		return err
+	}
+
+	// Um banco em memória sempre começa vazio
+	if a.Config.Database.AutoMigrate || EmMemoria(a.Config.Database) {
+		aplicadas, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, m := range aplicadas {
+			fmt.Printf("Migration %04d_%s aplicada\n", m.Versao, m.Nome)
+		}
+	}
+
+	pendentes, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pendentes) > 0 {
+		fmt.Printf("Atenção: %d migrations pendentes, o esquema do banco está desatualizado\n", len(pendentes))
+	}
+	return nil
+}
+
+// EmMemoria diz se os dados de cfg se perdem quando o processo termina
+func EmMemoria(cfg config.DatabaseConfig) bool {
+	return cfg.Driver == config.DriverMemory || (cfg.Driver == config.DriverSQLite && cfg.Path == ":memory:")
+}
+
+// newStores escolhe onde ficam usuários e tarefas: em memória com database.driver memory e no
+// banco configurado com os demais drivers
+func newStores(cfg config.DatabaseConfig, dbConnection *sql.DB) (repository.UsuarioStore, repository.TarefaStore) {
+	if cfg.Driver == config.DriverMemory {
+		usuarios := repository.NewUsuarioMemoryStore()
+		return usuarios, repository.NewTarefaMemoryStore(usuarios)
+	}
+	return repository.NewUsuarioRepository(dbConnection), repository.NewTarefaRepository(dbConnection)
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-api/app"
+	"go-api/config"
+)
+
+// ErrComandoDesconhecido é retornado por Run quando os argumentos não formam um comando
+var ErrComandoDesconhecido = errors.New("comando desconhecido")
+
+// Saídas dos comandos, trocadas nos testes
+var (
+	Stdin  io.Reader = os.Stdin
+	Stdout io.Writer = os.Stdout
+	Stderr io.Writer = os.Stderr
+)
+
+// comando é um comando da linha de comando, como "migrate up". prepara registra as flags do
+// comando e retorna a função que o executa depois que elas forem lidas.
+type comando struct {
+	nome      string
+	descricao string
+	// migrar indica que o comando usa o esquema e por isso aplica as migrations pendentes
+	// quando database.auto_migrate estiver ativo, como o servidor
+	migrar  bool
+	prepara func(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error
+}
+
+// Serve é a função que atende as requisições HTTP até ctx ser cancelado. Fica no main, onde
+// as rotas são registradas.
+type Serve func(ctx context.Context, a *app.App) error
+
+func comandos(serve Serve) []comando {
+	return []comando{
+		{
+			nome:      "serve",
+			descricao: "inicia o servidor HTTP (comando padrão)",
+			migrar:    true,
+			prepara: func(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+				return serve
+			},
+		},
+		{nome: "migrate up", descricao: "aplica as migrations pendentes", prepara: migrateUp},
+		{nome: "migrate down", descricao: "reverte as últimas migrations aplicadas", prepara: migrateDown},
+		{nome: "migrate status", descricao: "lista as migrations e quando foram aplicadas", prepara: migrateStatus},
+		{nome: "seed", descricao: "cria usuários e tarefas de exemplo para desenvolvimento", migrar: true, prepara: seed},
+		{nome: "user create", descricao: "cria um usuário, por exemplo o primeiro administrador", migrar: true, prepara: userCreate},
+		{nome: "user reset-password", descricao: "troca a senha de um usuário e encerra as sessões dele", migrar: true, prepara: userResetPassword},
+		{nome: "token issue", descricao: "abre uma sessão para um usuário e imprime os tokens", migrar: true, prepara: tokenIssue},
+	}
+}
+
+// Run executa o comando de args, que são os argumentos que sobram depois das flags de
+// configuração. Sem argumentos, inicia o servidor. Todos os comandos usam a mesma
+// configuração e a mesma montagem de repositórios e usecases do servidor.
+func Run(ctx context.Context, cfg *config.Config, args []string, serve Serve) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" {
+		uso(Stdout, comandos(serve))
+		return nil
+	}
+
+	c, resto, ok := encontra(comandos(serve), args)
+	if !ok {
+		uso(Stderr, comandos(serve))
+		return fmt.Errorf("%w: %s", ErrComandoDesconhecido, strings.Join(args, " "))
+	}
+
+	fs := flag.NewFlagSet("go-api "+c.nome, flag.ContinueOnError)
+	fs.SetOutput(Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Uso: go-api [flags de configuração] %s [flags]\n\n%s.\n", c.nome, strings.ToUpper(c.descricao[:1])+c.descricao[1:])
+		if temFlags(fs) {
+			fmt.Fprint(fs.Output(), "\nFlags:\n")
+			fs.PrintDefaults()
+		}
+	}
+	run := c.prepara(fs)
+	if err := fs.Parse(resto); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("go-api %s: argumento inesperado %q", c.nome, fs.Arg(0))
+	}
+
+	// Fora o servidor, os comandos alteram dados que se perderiam ao terminar
+	if c.nome != "serve" && app.EmMemoria(cfg.Database) {
+		return fmt.Errorf("go-api %s: o banco configurado fica em memória e perde os dados ao terminar; configure um banco persistente", c.nome)
+	}
+
+	a, err := app.Open(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := a.Close(); err != nil {
+			fmt.Fprintln(Stderr, err)
+		}
+	}()
+
+	if c.migrar {
+		if err := a.Migrar(ctx); err != nil {
+			return err
+		}
+	}
+	return run(ctx, a)
+}
+
+// encontra o comando de args, primeiro pelo nome com duas palavras, como "migrate up", e
+// depois com uma, como "seed". Retorna também os argumentos que sobram para as flags.
+func encontra(lista []comando, args []string) (comando, []string, bool) {
+	for _, palavras := range []int{2, 1} {
+		if len(args) < palavras {
+			continue
+		}
+		nome := strings.Join(args[:palavras], " ")
+		for _, c := range lista {
+			if c.nome == nome {
+				return c, args[palavras:], true
+			}
+		}
+	}
+	return comando{}, nil, false
+}
+
+func uso(w io.Writer, lista []comando) {
+	fmt.Fprint(w, "Uso: go-api [flags de configuração] [comando] [flags]\n\nComandos:\n")
+	for _, c := range lista {
+		fmt.Fprintf(w, "  %-21s %s\n", c.nome, c.descricao)
+	}
+	fmt.Fprint(w, "\nUse go-api -h para ver as flags de configuração e go-api <comando> -h para ver as flags de um comando.\n")
+}
+
+func temFlags(fs *flag.FlagSet) bool {
+	tem := false
+	fs.VisitAll(func(*flag.Flag) { tem = true })
+	return tem
+}
+
+// obrigatoria retorna um erro quando a flag nome não foi informada
+func obrigatoria(nome, valor string) error {
+	if valor == "" {
+		return fmt.Errorf("informe -%s", nome)
+	}
+	return nil
+}
+
+// lerSenha retorna a senha da flag ou, se ela não foi informada, lê a primeira linha da
+// entrada padrão. Assim a senha não precisa aparecer na lista de processos nem no histórico
+// do shell: echo "$SENHA" | go-api user create -login admin -name Admin -admin
+func lerSenha(senha string) (string, error) {
+	if senha != "" {
+		return senha, nil
+	}
+	if f, ok := Stdin.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(Stderr, "Senha: ")
+		}
+	}
+
+	linha, err := bufio.NewReader(Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	senha = strings.TrimRight(linha, "\r\n")
+	if senha == "" {
+		return "", errors.New("informe a senha com -password ou pela entrada padrão")
+	}
+	return senha, nil
+}
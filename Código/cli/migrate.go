@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"go-api/app"
+)
+
+func migrateUp(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	return func(ctx context.Context, a *app.App) error {
+		migrator, err := a.Migrator()
+		if err != nil {
+			return err
+		}
+		aplicadas, err := migrator.Up(ctx)
+		for _, m := range aplicadas {
+			fmt.Fprintf(Stdout, "Migration %04d_%s aplicada\n", m.Versao, m.Nome)
+		}
+		if err != nil {
+			return err
+		}
+		if len(aplicadas) == 0 {
+			fmt.Fprintf(Stdout, "O esquema já está na versão %04d\n", migrator.Versao())
+		}
+		return nil
+	}
+}
+
+func migrateDown(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	passos := fs.Int("steps", 1, "quantidade de migrations a reverter, da mais nova para a mais antiga")
+	return func(ctx context.Context, a *app.App) error {
+		if *passos <= 0 {
+			return errors.New("-steps precisa ser maior que zero")
+		}
+		migrator, err := a.Migrator()
+		if err != nil {
+			return err
+		}
+		revertidas, err := migrator.Down(ctx, *passos)
+		for _, m := range revertidas {
+			fmt.Fprintf(Stdout, "Migration %04d_%s revertida\n", m.Versao, m.Nome)
+		}
+		if err != nil {
+			return err
+		}
+		if len(revertidas) == 0 {
+			fmt.Fprintln(Stdout, "Nenhuma migration aplicada para reverter")
+		}
+		return nil
+	}
+}
+
+func migrateStatus(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	return func(ctx context.Context, a *app.App) error {
+		migrator, err := a.Migrator()
+		if err != nil {
+			return err
+		}
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSÃO\tNOME\tAPLICADA EM")
+		for _, s := range status {
+			nome, aplicadaEm := s.Nome, "pendente"
+			if nome == "" {
+				nome = "(desconhecida)"
+			}
+			if s.AplicadaEm != nil {
+				aplicadaEm = s.AplicadaEm.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Versao, nome, aplicadaEm)
+		}
+		return w.Flush()
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"go-api/app"
+	"go-api/model"
+	"go-api/usecase"
+)
+
+// Dados de exemplo criados por seed. Cada usuário vem com as próprias tarefas.
+var exemplos = []struct {
+	usuario model.Usuario
+	tarefas []model.Tarefa
+}{
+	{
+		usuario: model.Usuario{Nome: "Administrador", Login: "admin", Papel: model.PapelAdmin},
+	},
+	{
+		usuario: model.Usuario{Nome: "João Silva", Login: "joao", Papel: model.PapelMembro},
+		tarefas: []model.Tarefa{
+			{Nome: "Estudar Go", Conteudo: "Interfaces e generics", Finalizado: "N"},
+			{Nome: "Configurar ambiente", Conteudo: "Docker e banco de dados", Finalizado: "S"},
+		},
+	},
+	{
+		usuario: model.Usuario{Nome: "Maria Souza", Login: "maria", Papel: model.PapelMembro},
+		tarefas: []model.Tarefa{
+			{Nome: "Revisar PR", Conteudo: "Rotas de tarefas", Finalizado: "N"},
+		},
+	},
+}
+
+// seed pode ser executado mais de uma vez: usuários cujo login já existe são mantidos como
+// estão, sem tarefas novas
+func seed(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	senha := fs.String("password", "senha123", "senha dos usuários de exemplo")
+	return func(ctx context.Context, a *app.App) error {
+		for _, exemplo := range exemplos {
+			novo := exemplo.usuario
+			novo.Senha = *senha
+			usuario, err := a.UsuarioUseCase.CreateUsuario(novo)
+			if errors.Is(err, usecase.ErrLoginEmUso) {
+				fmt.Fprintf(Stdout, "Usuário %s já existe\n", novo.Login)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(Stdout, "Usuário %d criado: %s (%s)\n", usuario.Id, usuario.Login, usuario.Papel)
+
+			caller := model.Caller{UsuarioId: usuario.Id, Papel: usuario.Papel}
+			for _, tarefa := range exemplo.tarefas {
+				if _, err := a.TarefaUseCase.CreateTarefa(caller, tarefa); err != nil {
+					return err
+				}
+			}
+			if len(exemplo.tarefas) > 0 {
+				fmt.Fprintf(Stdout, "Tarefas de exemplo de %s: %d\n", usuario.Login, len(exemplo.tarefas))
+			}
+		}
+		fmt.Fprintf(Stdout, "Os usuários de exemplo entram com a senha %q\n", *senha)
+		return nil
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"go-api/app"
+	"go-api/model"
+)
+
+func userCreate(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	nome := fs.String("name", "", "nome do usuário")
+	login := fs.String("login", "", "login do usuário")
+	senha := fs.String("password", "", "senha do usuário; se omitida, é lida da entrada padrão")
+	admin := fs.Bool("admin", false, "cria o usuário como administrador")
+	return func(ctx context.Context, a *app.App) error {
+		if err := obrigatoria("name", *nome); err != nil {
+			return err
+		}
+		if err := obrigatoria("login", *login); err != nil {
+			return err
+		}
+		senha, err := lerSenha(*senha)
+		if err != nil {
+			return err
+		}
+
+		papel := model.PapelMembro
+		if *admin {
+			papel = model.PapelAdmin
+		}
+		usuario, err := a.UsuarioUseCase.CreateUsuario(model.Usuario{Nome: *nome, Login: *login, Senha: senha, Papel: papel})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(Stdout, "Usuário %d criado: %s (%s)\n", usuario.Id, usuario.Login, usuario.Papel)
+		return nil
+	}
+}
+
+func userResetPassword(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	login := fs.String("login", "", "login do usuário")
+	senha := fs.String("password", "", "nova senha; se omitida, é lida da entrada padrão")
+	return func(ctx context.Context, a *app.App) error {
+		if err := obrigatoria("login", *login); err != nil {
+			return err
+		}
+		senha, err := lerSenha(*senha)
+		if err != nil {
+			return err
+		}
+
+		if err := a.ResetSenhaUseCase.SetPassword(*login, senha); err != nil {
+			return err
+		}
+		fmt.Fprintf(Stdout, "Senha de %s trocada; as sessões do usuário foram encerradas\n", *login)
+		return nil
+	}
+}
+
+// tokenIssue imprime o par de tokens em JSON, no mesmo formato da resposta de /auth/login.
+// A sessão aberta aparece em /auth/sessions e pode ser encerrada como qualquer outra.
+func tokenIssue(fs *flag.FlagSet) func(ctx context.Context, a *app.App) error {
+	login := fs.String("login", "", "login do usuário")
+	return func(ctx context.Context, a *app.App) error {
+		if err := obrigatoria("login", *login); err != nil {
+			return err
+		}
+		// A chave temporária muda a cada execução e o servidor não aceitaria o token
+		if a.Config.JWT.KeysFile == "" && a.Config.JWT.Secret == "" {
+			return errors.New("configure jwt.keys_file ou jwt.secret para emitir tokens aceitos pelo servidor")
+		}
+
+		tokens, err := a.AuthUseCase.IssueToken(*login, model.Origem{UserAgent: "go-api token issue"})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tokens)
+	}
+}
@@ -1,11 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-api/app"
+	"go-api/cli"
+	"go-api/config"
 	"go-api/controller"
 	"go-api/db"
 	docs "go-api/docs"
-	"go-api/repository"
+	"go-api/httpserver"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/oidc"
 	"go-api/usecase"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -28,28 +42,94 @@ func main() {
 	// @host      localhost:8000
 	// @BasePath  /
 
+	// @securityDefinitions.apikey BearerAuth
+	// @in header
+	// @name Authorization
+	// @description Informe "Bearer {token}" com o token retornado por /auth/login ou "Bearer {api_key}"
+
+	// @securityDefinitions.apikey ApiKeyAuth
+	// @in header
+	// @name X-API-Key
+	// @description API key criada em /api-keys
+
+	cfg, args, err := config.LoadArgs(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// SIGINT e SIGTERM cancelam ctx, o que encerra o servidor e as rotinas em segundo plano
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cli.Run(ctx, cfg, args, serve); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+// serve registra as rotas e atende as requisições até ctx ser cancelado. Ao receber o sinal
+// de encerramento, espera as requisições em andamento e as rotinas em segundo plano.
+func serve(ctx context.Context, a *app.App) error {
+	cfg := a.Config
+
 	docs.SwaggerInfo.BasePath = "/"
-	server := gin.Default()
-	docs.SwaggerInfo.BasePath = "/"
+	server, err := httpserver.NewRouter(cfg.Server)
+	if err != nil {
+		return err
+	}
 
-	dbConnection, err := db.ConnectDB()
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	migrator, err := a.Migrator()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	// camada de repository
-	UsuarioRepository := repository.NewUsuarioRepository(dbConnection)
-	TarefaRepository := repository.NewTarefaRepository(dbConnection)
+	rotinas := usecase.NewRotinas()
+	rotinas.Run(ctx, "monitor-banco", func(ctx context.Context) { db.Monitor(ctx, a.DB, 30*time.Second) })
 
-	// camada usecase
-	UsuarioUseCase := usecase.NewUsuarioUseCase(UsuarioRepository)
-	TarefaUseCase := usecase.NewTarefaUseCase(TarefaRepository)
-	AuthUseCase := usecase.NewAuthUsecase(UsuarioRepository)
+	if err := a.TokenUseCase.LoadRevokedTokens(); err != nil {
+		fmt.Println("Não foi possível carregar os tokens revogados:", err)
+	}
+	// Remove periodicamente as revogações, os refresh tokens, as sessões, as falhas de login e os tokens de redefinição já expirados
+	rotinas.Run(ctx, "limpeza-tokens", func(ctx context.Context) { a.TokenUseCase.RunCleanup(ctx, time.Hour) })
+	rotinas.Run(ctx, "limpeza-tentativas-login", func(ctx context.Context) { a.TentativaLoginUseCase.RunCleanup(ctx, time.Hour) })
+	rotinas.Run(ctx, "limpeza-reset-senha", func(ctx context.Context) { a.ResetSenhaUseCase.RunCleanup(ctx, time.Hour) })
+	SaudeUseCase := usecase.NewSaudeUsecase(a.DB, migrator, rotinas, cfg.Server.ReadinessTimeout.Duration)
 
 	// camada de controllers
-	usuarioController := controller.NewUsuarioController(UsuarioUseCase)
-	tarefaController := controller.NewTarefaController(TarefaUseCase)
-	authController := controller.NewAuthController(AuthUseCase)
+	usuarioController := controller.NewUsuarioController(a.UsuarioUseCase)
+	tarefaController := controller.NewTarefaController(a.TarefaUseCase)
+	authController := controller.NewAuthController(a.AuthUseCase)
+	resetSenhaController := controller.NewResetSenhaController(a.ResetSenhaUseCase)
+	doisFatoresController := controller.NewDoisFatoresController(a.DoisFatoresUseCase)
+	apiKeyController := controller.NewApiKeyController(a.ApiKeyUseCase)
+	saudeController := controller.NewSaudeController(SaudeUseCase)
+
+	// Todas as rotas exigem token ou API key, exceto as listadas aqui
+	server.Use(middleware.Auth(a.AuthUseCase,
+		"/ping",
+		"/healthz",
+		"/readyz",
+		"/auth/login",
+		"/auth/refresh",
+		"/auth/forgot-password",
+		"/auth/reset-password",
+		"/auth/2fa/verify",
+		"/auth/oidc/login",
+		"/auth/oidc/callback",
+		"/.well-known/jwks.json",
+		"/swagger/*any",
+	))
 
 	auth := server.Group("/auth")
 
@@ -60,29 +140,77 @@ func main() {
 		})
 	})
 
-	// Rotas de usuário
-	server.GET("/usuarios", usuarioController.GetUsuarios)
-	server.POST("/usuario", usuarioController.CreateUsuario)
-	server.GET("/usuario/:usuarioId", usuarioController.GetUsuarioById)
-	server.PUT("/usuario/:usuarioId", usuarioController.UpdateUsuarioById)
-	server.DELETE("/usuario/:usuarioId", usuarioController.SoftDeleteUsuarioById)
+	// Verificações de vivacidade e de prontidão para o orquestrador e o balanceador de carga
+	server.GET("/healthz", saudeController.Healthz)
+	server.GET("/readyz", saudeController.Readyz)
+
+	// Rotas de usuário: administradores gerenciam qualquer usuário, membros apenas a si mesmos
+	adminOnly := middleware.RequireRole(model.PapelAdmin)
+	selfOrAdmin := middleware.RequireSelfOrRole("usuarioId", model.PapelAdmin)
+	// Requisições com API key precisam do escopo correspondente; com JWT não há restrição
+	usuariosRead := middleware.RequireScope(model.EscopoUsuariosLeitura)
+	usuariosWrite := middleware.RequireScope(model.EscopoUsuariosEscrita)
+	tarefasRead := middleware.RequireScope(model.EscopoTarefasLeitura)
+	tarefasWrite := middleware.RequireScope(model.EscopoTarefasEscrita)
+	sessionOnly := middleware.RequireSession()
+
+	server.GET("/usuarios", usuariosRead, adminOnly, usuarioController.GetUsuarios)
+	server.POST("/usuario", usuariosWrite, adminOnly, usuarioController.CreateUsuario)
+	server.GET("/usuario/:usuarioId", usuariosRead, selfOrAdmin, usuarioController.GetUsuarioById)
+	server.PUT("/usuario/:usuarioId", usuariosWrite, selfOrAdmin, usuarioController.UpdateUsuarioById)
+	server.DELETE("/usuario/:usuarioId", usuariosWrite, selfOrAdmin, usuarioController.SoftDeleteUsuarioById)
+	server.PUT("/usuario/:usuarioId/papel", usuariosWrite, adminOnly, usuarioController.UpdatePapelById)
+	server.PUT("/usuario/:usuarioId/status", usuariosWrite, adminOnly, usuarioController.UpdateStatusById)
+	server.DELETE("/usuario/:usuarioId/bloqueio", usuariosWrite, adminOnly, authController.DesbloquearUsuario)
 
 	// Rotas de tarefa
-	server.GET("/tarefas", tarefaController.GetTarefas)
-	server.POST("/tarefa", tarefaController.CreateTarefa)
-	server.GET("/tarefa/:tarefaId", tarefaController.GetTarefaById)
-	server.GET("/tarefausuario/:usuarioId", tarefaController.GetTarefasByUsuarioId)
-	server.PUT("/tarefa/:tarefaId", tarefaController.UpdateTarefaById)
-	server.DELETE("/tarefa/:tarefaId", tarefaController.SoftDeleteTarefaById)
+	server.GET("/tarefas", tarefasRead, tarefaController.GetTarefas)
+	server.POST("/tarefa", tarefasWrite, tarefaController.CreateTarefa)
+	server.GET("/tarefa/:tarefaId", tarefasRead, tarefaController.GetTarefaById)
+	server.GET("/tarefausuario/:usuarioId", tarefasRead, tarefaController.GetTarefasByUsuarioId)
+	server.PUT("/tarefa/:tarefaId", tarefasWrite, tarefaController.UpdateTarefaById)
+	server.DELETE("/tarefa/:tarefaId", tarefasWrite, tarefaController.SoftDeleteTarefaById)
+
+	// API keys são gerenciadas apenas com o login do próprio usuário
+	server.POST("/api-keys", sessionOnly, apiKeyController.CreateApiKey)
+	server.GET("/api-keys", sessionOnly, apiKeyController.GetApiKeys)
+	server.DELETE("/api-keys/:apiKeyId", sessionOnly, apiKeyController.RevokeApiKey)
 
 	// Autenticação
 	auth.POST("/login", authController.Login)
-	auth.POST("/logout", authController.Logout)
+	auth.POST("/refresh", authController.Refresh)
+	server.GET("/.well-known/jwks.json", authController.JWKS)
+	auth.POST("/logout", sessionOnly, authController.Logout)
+	auth.POST("/forgot-password", resetSenhaController.ForgotPassword)
+	auth.POST("/reset-password", resetSenhaController.ResetPassword)
+	auth.POST("/2fa/enroll", sessionOnly, doisFatoresController.Enroll)
+	auth.POST("/2fa/confirm", sessionOnly, doisFatoresController.Confirm)
+	auth.POST("/2fa/verify", authController.VerifyDoisFatores)
+	auth.GET("/me", usuariosRead, authController.Me)
+	auth.PATCH("/me", usuariosWrite, authController.UpdateMe)
+	auth.POST("/change-password", sessionOnly, authController.ChangePassword)
+	auth.GET("/sessions", sessionOnly, authController.GetSessoes)
+	auth.DELETE("/sessions/:sessaoId", sessionOnly, authController.EncerrarSessao)
+
+	// Login pelo provedor OpenID Connect, apenas quando oidc.issuer estiver configurado
+	if oidcConfig := oidc.NewConfig(cfg.OIDC); oidcConfig != nil {
+		OidcUseCase := usecase.NewOidcUsecase(oidc.NewProvider(*oidcConfig, nil), a.UsuarioStore, a.IdentidadeExternaRepository, a.TokenUseCase, cfg.Password)
+		oidcController := controller.NewOidcController(OidcUseCase)
+		auth.GET("/oidc/login", oidcController.Login)
+		auth.GET("/oidc/callback", oidcController.Callback)
+	}
 
 	// Documentação Swagger
 	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Starta o servidor
-	server.Run(":8000")
-
+	// Starta o servidor e, ao receber o sinal de encerramento, espera as requisições em
+	// andamento e as rotinas em segundo plano
+	err = httpserver.Run(ctx, httpserver.New(cfg.Server, server), cfg.Server)
+	stop()
+	rotinas.Wait()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Servidor encerrado")
+	return nil
 }
@@ -0,0 +1,529 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne toda a configuração da API. Load monta a configuração a partir dos valores
+// padrão, do arquivo YAML ou TOML, das variáveis de ambiente e das flags, nessa ordem: cada
+// fonte sobrescreve apenas o que define.
+type Config struct {
+	Server          ServerConfig          `yaml:"server" toml:"server"`
+	Database        DatabaseConfig        `yaml:"database" toml:"database"`
+	JWT             JWTConfig             `yaml:"jwt" toml:"jwt"`
+	LoginProtection LoginProtectionConfig `yaml:"login" toml:"login"`
+	Password        PasswordConfig        `yaml:"password" toml:"password"`
+	ResetSenha      ResetSenhaConfig      `yaml:"reset_senha" toml:"reset_senha"`
+	Notification    NotificationConfig    `yaml:"notification" toml:"notification"`
+	OIDC            OIDCConfig            `yaml:"oidc" toml:"oidc"`
+}
+
+// ServerConfig ajusta o servidor HTTP. shutdown_timeout é o prazo para as requisições em
+// andamento terminarem quando o processo recebe SIGINT ou SIGTERM. X-Forwarded-For e
+// X-Real-IP só valem em conexões vindas de trusted_proxies; sem nenhum, o IP do cliente é o
+// endereço da conexão.
+type ServerConfig struct {
+	Addr              string   `yaml:"addr" toml:"addr"`
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	MaxHeaderBytes    int      `yaml:"max_header_bytes" toml:"max_header_bytes"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	ReadinessTimeout  Duration `yaml:"readiness_timeout" toml:"readiness_timeout"`
+	TrustedProxies    []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// Backends de armazenamento aceitos em database.driver
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Valores de database.sslmode aceitos pelo driver do PostgreSQL
+var sslModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// DatabaseConfig descreve a conexão com o banco. Com os drivers mysql e postgres são usados
+// host, port, user, password e name; a senha pode vir de password_file, como nos secrets do
+// Docker e do Kubernetes. sslmode vale apenas para o postgres. Com o driver sqlite basta path,
+// que aceita ":memory:" para um banco em memória. O driver memory guarda usuários e tarefas
+// em estruturas em memória e os demais dados em um SQLite em memória, sem nenhuma
+// configuração; tudo se perde quando o processo termina.
+// connect_timeout limita a espera pelo banco na inicialização e auto_migrate aplica as
+// migrations pendentes logo após a conexão; os demais campos ajustam o pool de conexões.
+type DatabaseConfig struct {
+	Driver          string   `yaml:"driver" toml:"driver"`
+	Path            string   `yaml:"path" toml:"path"`
+	Host            string   `yaml:"host" toml:"host"`
+	Port            int      `yaml:"port" toml:"port"`
+	User            string   `yaml:"user" toml:"user"`
+	Password        string   `yaml:"password" toml:"password"`
+	PasswordFile    string   `yaml:"password_file" toml:"password_file"`
+	Name            string   `yaml:"name" toml:"name"`
+	SSLMode         string   `yaml:"sslmode" toml:"sslmode"`
+	ConnectTimeout  Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	AutoMigrate     bool     `yaml:"auto_migrate" toml:"auto_migrate"`
+}
+
+// JWTConfig indica as chaves de assinatura: o arquivo JSON de KeysConfig em keys_file ou um
+// segredo HS256 em secret (ou secret_file). Sem nenhum dos dois, uma chave temporária é usada.
+type JWTConfig struct {
+	KeysFile             string   `yaml:"keys_file" toml:"keys_file"`
+	Secret               string   `yaml:"secret" toml:"secret"`
+	SecretFile           string   `yaml:"secret_file" toml:"secret_file"`
+	AccessTokenDuration  Duration `yaml:"access_token_duration" toml:"access_token_duration"`
+	RefreshTokenDuration Duration `yaml:"refresh_token_duration" toml:"refresh_token_duration"`
+}
+
+// LoginProtectionConfig limita as falhas de login antes do bloqueio. As falhas são contadas por
+// login e por IP; o IP tolera mais falhas porque vários usuários podem compartilhar o mesmo endereço.
+type LoginProtectionConfig struct {
+	MaxFalhas       int      `yaml:"max_falhas" toml:"max_falhas"`
+	IPMaxFalhas     int      `yaml:"ip_max_falhas" toml:"ip_max_falhas"`
+	BloqueioDuracao Duration `yaml:"bloqueio_duracao" toml:"bloqueio_duracao"`
+}
+
+// PasswordConfig define o custo do bcrypt usado para novas senhas. Hashes gravados com outro
+// custo são refeitos no próximo login bem-sucedido.
+type PasswordConfig struct {
+	Cost int `yaml:"cost" toml:"cost"`
+}
+
+// ResetSenhaConfig ajusta a recuperação de senha. url é a página do front-end que recebe o
+// token de redefinição; vazia, a notificação traz apenas o token, a ser enviado para
+// /auth/reset-password.
+type ResetSenhaConfig struct {
+	URL     string   `yaml:"url" toml:"url"`
+	Duracao Duration `yaml:"duracao" toml:"duracao"`
+}
+
+// NotificationConfig indica o arquivo que recebe as notificações. Vazio, elas vão para a saída padrão.
+type NotificationConfig struct {
+	File string `yaml:"file" toml:"file"`
+}
+
+// OIDCConfig descreve o cliente registrado no provedor OpenID Connect. Sem issuer, o login OIDC fica desativado.
+type OIDCConfig struct {
+	Issuer           string `yaml:"issuer" toml:"issuer"`
+	ClientId         string `yaml:"client_id" toml:"client_id"`
+	ClientSecret     string `yaml:"client_secret" toml:"client_secret"`
+	ClientSecretFile string `yaml:"client_secret_file" toml:"client_secret_file"`
+	RedirectURL      string `yaml:"redirect_url" toml:"redirect_url"`
+}
+
+// Duration aceita durações no formato de time.ParseDuration (ex.: "15m") nos arquivos de configuração
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Default retorna a configuração usada quando nenhuma fonte define um valor
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8000",
+			ReadTimeout:       Duration{15 * time.Second},
+			ReadHeaderTimeout: Duration{5 * time.Second},
+			WriteTimeout:      Duration{30 * time.Second},
+			IdleTimeout:       Duration{2 * time.Minute},
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   Duration{20 * time.Second},
+			ReadinessTimeout:  Duration{2 * time.Second},
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverMySQL,
+			Host:            "localhost",
+			Port:            3306,
+			User:            "master",
+			Name:            "trabgb",
+			SSLMode:         "disable",
+			ConnectTimeout:  Duration{30 * time.Second},
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration{5 * time.Minute},
+			AutoMigrate:     true,
+		},
+		JWT: JWTConfig{
+			AccessTokenDuration:  Duration{15 * time.Minute},
+			RefreshTokenDuration: Duration{30 * 24 * time.Hour},
+		},
+		LoginProtection: LoginProtectionConfig{
+			MaxFalhas:       5,
+			IPMaxFalhas:     20,
+			BloqueioDuracao: Duration{15 * time.Minute},
+		},
+		Password:   PasswordConfig{Cost: 12},
+		ResetSenha: ResetSenhaConfig{Duracao: Duration{30 * time.Minute}},
+	}
+}
+
+// campo liga uma chave da configuração à variável de ambiente e à flag que a sobrescrevem.
+// A flag é a chave com pontos e sublinhados trocados por hífens (database.password_file vira
+// -database-password-file). exclui aponta o par de um segredo: definir a senha por uma fonte
+// descarta o arquivo de senha definido por uma fonte anterior, e vice-versa.
+type campo struct {
+	chave     string
+	env       string
+	valor     interface{}
+	exclui    *string
+	descricao string
+}
+
+func (c *Config) campos() []campo {
+	return []campo{
+		{"server.addr", "SERVER_ADDR", &c.Server.Addr, nil, "endereço em que o servidor HTTP escuta"},
+		{"server.read_timeout", "SERVER_READ_TIMEOUT", &c.Server.ReadTimeout, nil, "tempo máximo para ler uma requisição inteira"},
+		{"server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT", &c.Server.ReadHeaderTimeout, nil, "tempo máximo para ler os cabeçalhos"},
+		{"server.write_timeout", "SERVER_WRITE_TIMEOUT", &c.Server.WriteTimeout, nil, "tempo máximo para escrever a resposta"},
+		{"server.idle_timeout", "SERVER_IDLE_TIMEOUT", &c.Server.IdleTimeout, nil, "tempo que uma conexão keep-alive ociosa é mantida"},
+		{"server.max_header_bytes", "SERVER_MAX_HEADER_BYTES", &c.Server.MaxHeaderBytes, nil, "tamanho máximo dos cabeçalhos da requisição"},
+		{"server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout, nil, "prazo para concluir as requisições em andamento ao encerrar"},
+		{"server.readiness_timeout", "SERVER_READINESS_TIMEOUT", &c.Server.ReadinessTimeout, nil, "prazo do /readyz para consultar o banco de dados"},
+		{"server.trusted_proxies", "SERVER_TRUSTED_PROXIES", &c.Server.TrustedProxies, nil, "IPs ou redes dos proxies cujo X-Forwarded-For é aceito, separados por vírgula"},
+		{"database.driver", "DB_DRIVER", &c.Database.Driver, nil, "backend de armazenamento: mysql, postgres, sqlite ou memory"},
+		{"database.path", "DB_PATH", &c.Database.Path, nil, "arquivo do banco SQLite ou :memory:"},
+		{"database.host", "DB_HOST", &c.Database.Host, nil, "host do MySQL ou do PostgreSQL"},
+		{"database.port", "DB_PORT", &c.Database.Port, nil, "porta do MySQL ou do PostgreSQL"},
+		{"database.user", "DB_USER", &c.Database.User, nil, "usuário do banco de dados"},
+		{"database.password", "DB_PASSWORD", &c.Database.Password, &c.Database.PasswordFile, "senha do banco de dados"},
+		{"database.password_file", "DB_PASSWORD_FILE", &c.Database.PasswordFile, &c.Database.Password, "arquivo com a senha do banco de dados"},
+		{"database.name", "DB_NAME", &c.Database.Name, nil, "nome do banco de dados"},
+		{"database.sslmode", "DB_SSLMODE", &c.Database.SSLMode, nil, "sslmode do PostgreSQL: disable, require, verify-ca ou verify-full"},
+		{"database.connect_timeout", "DB_CONNECT_TIMEOUT", &c.Database.ConnectTimeout, nil, "tempo máximo de espera pelo banco na inicialização"},
+		{"database.max_open_conns", "DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns, nil, "máximo de conexões abertas"},
+		{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns, nil, "máximo de conexões ociosas mantidas no pool"},
+		{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime, nil, "tempo máximo de reuso de uma conexão"},
+		{"database.auto_migrate", "DB_AUTO_MIGRATE", &c.Database.AutoMigrate, nil, "aplica as migrations pendentes na inicialização"},
+		{"jwt.keys_file", "JWT_KEYS_FILE", &c.JWT.KeysFile, nil, "arquivo JSON com as chaves de assinatura"},
+		{"jwt.secret", "JWT_SECRET", &c.JWT.Secret, &c.JWT.SecretFile, "segredo HS256 dos tokens"},
+		{"jwt.secret_file", "JWT_SECRET_FILE", &c.JWT.SecretFile, &c.JWT.Secret, "arquivo com o segredo HS256 dos tokens"},
+		{"jwt.access_token_duration", "JWT_ACCESS_TOKEN_DURATION", &c.JWT.AccessTokenDuration, nil, "validade dos tokens de acesso"},
+		{"jwt.refresh_token_duration", "JWT_REFRESH_TOKEN_DURATION", &c.JWT.RefreshTokenDuration, nil, "validade dos refresh tokens"},
+		{"login.max_falhas", "LOGIN_MAX_FALHAS", &c.LoginProtection.MaxFalhas, nil, "falhas por login até o bloqueio"},
+		{"login.ip_max_falhas", "LOGIN_IP_MAX_FALHAS", &c.LoginProtection.IPMaxFalhas, nil, "falhas por IP até o bloqueio"},
+		{"login.bloqueio_duracao", "LOGIN_BLOQUEIO_DURACAO", &c.LoginProtection.BloqueioDuracao, nil, "duração do bloqueio de login"},
+		{"password.cost", "PASSWORD_COST", &c.Password.Cost, nil, "custo do bcrypt das novas senhas"},
+		{"reset_senha.url", "RESET_SENHA_URL", &c.ResetSenha.URL, nil, "página do front-end que recebe o token de redefinição"},
+		{"reset_senha.duracao", "RESET_SENHA_DURACAO", &c.ResetSenha.Duracao, nil, "validade dos tokens de redefinição de senha"},
+		{"notification.file", "NOTIFICATION_FILE", &c.Notification.File, nil, "arquivo que recebe as notificações"},
+		{"oidc.issuer", "OIDC_ISSUER", &c.OIDC.Issuer, nil, "issuer do provedor OpenID Connect"},
+		{"oidc.client_id", "OIDC_CLIENT_ID", &c.OIDC.ClientId, nil, "client_id registrado no provedor"},
+		{"oidc.client_secret", "OIDC_CLIENT_SECRET", &c.OIDC.ClientSecret, &c.OIDC.ClientSecretFile, "client_secret registrado no provedor"},
+		{"oidc.client_secret_file", "OIDC_CLIENT_SECRET_FILE", &c.OIDC.ClientSecretFile, &c.OIDC.ClientSecret, "arquivo com o client_secret"},
+		{"oidc.redirect_url", "OIDC_REDIRECT_URL", &c.OIDC.RedirectURL, nil, "URL de /auth/oidc/callback registrada no provedor"},
+	}
+}
+
+func (c campo) flag() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(c.chave)
+}
+
+func (c campo) set(value string) error {
+	switch p := c.valor.(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q não é um número inteiro", value)
+		}
+		*p = n
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q não é um booleano (use true ou false)", value)
+		}
+		*p = b
+	case *[]string:
+		*p = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	case *Duration:
+		if err := p.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%q não é uma duração válida (ex.: 30s, 15m, 24h)", value)
+		}
+	}
+	if c.exclui != nil {
+		*c.exclui = ""
+	}
+	return nil
+}
+
+// Load monta a configuração a partir de args (normalmente os.Args[1:]). O arquivo é indicado
+// pela flag -config ou por CONFIG_FILE. Os segredos lidos de arquivo já vêm preenchidos e a
+// configuração retornada já foi validada.
+func Load(args []string) (*Config, error) {
+	cfg, _, err := LoadArgs(args)
+	return cfg, err
+}
+
+// LoadArgs é como Load, mas também retorna os argumentos que sobram depois das flags de
+// configuração, como o comando e as flags dele em "go-api -config api.yaml migrate up"
+func LoadArgs(args []string) (*Config, []string, error) {
+	cfg := Default()
+
+	// As flags são lidas primeiro para descobrir o arquivo, mas só são aplicadas no final
+	type definida struct {
+		campo campo
+		valor string
+	}
+	var flags []definida
+	fs := flag.NewFlagSet("go-api", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), "Uso: go-api [flags] [comando]\n\nExecute go-api help para ver os comandos.\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração YAML ou TOML")
+	for _, c := range cfg.campos() {
+		c := c
+		registra := fs.Func
+		if _, ok := c.valor.(*bool); ok {
+			// Flags booleanas podem vir sem valor, como -database-auto-migrate
+			registra = fs.BoolFunc
+		}
+		registra(c.flag(), c.descricao+" ("+c.env+")", func(value string) error {
+			flags = append(flags, definida{c, value})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, c := range cfg.campos() {
+		if value, ok := os.LookupEnv(c.env); ok {
+			if err := c.set(value); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", c.env, err)
+			}
+		}
+	}
+
+	for _, f := range flags {
+		if err := f.campo.set(f.valor); err != nil {
+			return nil, nil, fmt.Errorf("-%s: %w", f.campo.flag(), err)
+		}
+	}
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// loadFile sobrescreve a configuração com o arquivo. Chaves desconhecidas são recusadas
+// para que erros de digitação não passem despercebidos.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("arquivo de configuração: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(c)
+		// Um arquivo vazio mantém os valores anteriores
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	default:
+		return fmt.Errorf("arquivo de configuração %s: use a extensão .yaml, .yml ou .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("arquivo de configuração %s: %w", path, err)
+	}
+	return nil
+}
+
+// resolveSecrets lê os segredos configurados por arquivo
+func (c *Config) resolveSecrets() error {
+	segredos := []struct {
+		chave       string
+		valor, file *string
+	}{
+		{"database.password_file", &c.Database.Password, &c.Database.PasswordFile},
+		{"jwt.secret_file", &c.JWT.Secret, &c.JWT.SecretFile},
+		{"oidc.client_secret_file", &c.OIDC.ClientSecret, &c.OIDC.ClientSecretFile},
+	}
+	for _, s := range segredos {
+		if *s.file == "" {
+			continue
+		}
+		if *s.valor != "" {
+			return fmt.Errorf("%s: informe o segredo ou o arquivo, não ambos", s.chave)
+		}
+		secret, err := readSecret("", *s.file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.chave, err)
+		}
+		*s.valor = secret
+	}
+	return nil
+}
+
+// Validate confere a configuração e reporta todos os problemas encontrados de uma vez
+func (c *Config) Validate() error {
+	var errs []error
+	invalido := func(chave, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]interface{}{chave}, args...)...))
+	}
+
+	if c.Server.Addr == "" {
+		invalido("server.addr", "é obrigatório")
+	}
+	for _, limite := range []struct {
+		chave string
+		valor Duration
+	}{
+		{"server.read_timeout", c.Server.ReadTimeout},
+		{"server.read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"server.write_timeout", c.Server.WriteTimeout},
+		{"server.idle_timeout", c.Server.IdleTimeout},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout},
+		{"server.readiness_timeout", c.Server.ReadinessTimeout},
+	} {
+		if limite.valor.Duration <= 0 {
+			invalido(limite.chave, "deve ser positivo")
+		}
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			invalido("server.trusted_proxies", "%q não é um IP nem uma rede CIDR", proxy)
+		}
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		invalido("server.max_header_bytes", "deve ser positivo")
+	}
+
+	switch c.Database.Driver {
+	case DriverMySQL, DriverPostgres:
+		if c.Database.Host == "" {
+			invalido("database.host", "é obrigatório")
+		}
+		if c.Database.Port <= 0 || c.Database.Port > 65535 {
+			invalido("database.port", "%d não é uma porta válida", c.Database.Port)
+		}
+		if c.Database.User == "" {
+			invalido("database.user", "é obrigatório")
+		}
+		if c.Database.Name == "" {
+			invalido("database.name", "é obrigatório")
+		}
+		if c.Database.Driver == DriverPostgres && !slices.Contains(sslModes, c.Database.SSLMode) {
+			invalido("database.sslmode", "%q não é suportado, use disable, require, verify-ca ou verify-full", c.Database.SSLMode)
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			invalido("database.path", "é obrigatório com o driver sqlite")
+		}
+	case DriverMemory:
+	default:
+		invalido("database.driver", "%q não é suportado, use mysql, postgres, sqlite ou memory", c.Database.Driver)
+	}
+	if c.Database.ConnectTimeout.Duration <= 0 {
+		invalido("database.connect_timeout", "deve ser positivo")
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		invalido("database.max_open_conns", "deve ser positivo")
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		invalido("database.max_idle_conns", "deve estar entre 0 e database.max_open_conns")
+	}
+	if c.Database.ConnMaxLifetime.Duration < 0 {
+		invalido("database.conn_max_lifetime", "não pode ser negativo")
+	}
+
+	if c.JWT.KeysFile != "" && c.JWT.Secret != "" {
+		invalido("jwt.secret", "não pode ser usado junto com jwt.keys_file")
+	}
+	if c.JWT.Secret != "" && len(c.JWT.Secret) < 32 {
+		invalido("jwt.secret", "deve ter pelo menos 32 bytes")
+	}
+	if c.JWT.AccessTokenDuration.Duration <= 0 {
+		invalido("jwt.access_token_duration", "deve ser positivo")
+	}
+	if c.JWT.RefreshTokenDuration.Duration <= c.JWT.AccessTokenDuration.Duration {
+		invalido("jwt.refresh_token_duration", "deve ser maior que jwt.access_token_duration")
+	}
+
+	if c.LoginProtection.MaxFalhas <= 0 {
+		invalido("login.max_falhas", "deve ser positivo")
+	}
+	if c.LoginProtection.IPMaxFalhas <= 0 {
+		invalido("login.ip_max_falhas", "deve ser positivo")
+	}
+	if c.LoginProtection.BloqueioDuracao.Duration <= 0 {
+		invalido("login.bloqueio_duracao", "deve ser positivo")
+	}
+
+	if c.Password.Cost < bcrypt.MinCost || c.Password.Cost > bcrypt.MaxCost {
+		invalido("password.cost", "deve estar entre %d e %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	if c.ResetSenha.Duracao.Duration <= 0 {
+		invalido("reset_senha.duracao", "deve ser positivo")
+	}
+
+	if c.OIDC.Issuer != "" {
+		if c.OIDC.ClientId == "" {
+			invalido("oidc.client_id", "é obrigatório quando oidc.issuer está definido")
+		}
+		if c.OIDC.RedirectURL == "" {
+			invalido("oidc.redirect_url", "é obrigatório quando oidc.issuer está definido")
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("configuração inválida:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -1,19 +1,110 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("sua-chave-secreta")
+// Tempo para informar o código do segundo fator após a senha. Os tempos de vida dos tokens de
+// acesso e dos refresh tokens vêm de JWTConfig.
+const ChallengeTokenDuration = 5 * time.Minute
 
-func GenerateToken(userId int) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userId,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+// UsoDoisFatores marca os tokens de desafio emitidos entre a senha e o código TOTP. Eles
+// só servem para /auth/2fa/verify e nunca são aceitos como token de acesso.
+const UsoDoisFatores = "2fa"
+
+var ErrTokenInvalido = errors.New("token inválido ou expirado")
+
+type Claims struct {
+	UserId    int    `json:"user_id"`
+	Papel     string `json:"role"`
+	SessionId string `json:"sid,omitempty"`
+	Uso       string `json:"use,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken emite um token de acesso válido por duration. sessionId identifica a família
+// de refresh tokens que originou o token.
+func (ks *KeySet) GenerateToken(userId int, papel string, sessionId string, duration time.Duration) (string, error) {
+	return ks.signToken(Claims{UserId: userId, Papel: papel, SessionId: sessionId}, duration)
+}
+
+// GenerateChallengeToken emite o token de desafio de quem já informou a senha e ainda
+// precisa informar o código do segundo fator
+func (ks *KeySet) GenerateChallengeToken(userId int) (string, error) {
+	return ks.signToken(Claims{UserId: userId, Uso: UsoDoisFatores}, ChallengeTokenDuration)
+}
+
+func (ks *KeySet) signToken(claims Claims, duration time.Duration) (string, error) {
+	jti, err := RandomId()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+	}
+
+	key := ks.Active()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.signing)
+}
+
+// ParseToken valida assinatura, algoritmo e expiração do token de acesso e retorna suas claims.
+// A chave é escolhida pelo kid do cabeçalho e o algoritmo do token precisa ser o da chave.
+func (ks *KeySet) ParseToken(tokenString string) (*Claims, error) {
+	claims, err := ks.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Uso != "" {
+		return nil, ErrTokenInvalido
+	}
+	return claims, nil
+}
+
+// ParseChallengeToken valida um token emitido por GenerateChallengeToken
+func (ks *KeySet) ParseChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := ks.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Uso != UsoDoisFatores {
+		return nil, ErrTokenInvalido
+	}
+	return claims, nil
+}
+
+func (ks *KeySet) parseClaims(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key := ks.Key(kid)
+		if key == nil {
+			return nil, fmt.Errorf("kid %q desconhecido", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("algoritmo %s não corresponde à chave %q", token.Method.Alg(), kid)
+		}
+		return key.verification, nil
+	},
+		jwt.WithValidMethods(supportedAlgorithms),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, ErrTokenInvalido
+	}
+
+	if claims.UserId <= 0 || claims.ID == "" {
+		return nil, ErrTokenInvalido
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return claims, nil
 }
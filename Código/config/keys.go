@@ -0,0 +1,289 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritmos de assinatura aceitos para os tokens emitidos pela API
+var supportedAlgorithms = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// KeyConfig descreve uma chave de assinatura na configuração. Chaves HS256 usam secret
+// (ou secret_file); as assimétricas usam private_key_file em PEM. Uma chave só com
+// public_key_file serve apenas para validar tokens emitidos antes de uma rotação.
+type KeyConfig struct {
+	Kid            string `json:"kid"`
+	Algorithm      string `json:"alg"`
+	Secret         string `json:"secret,omitempty"`
+	SecretFile     string `json:"secret_file,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+}
+
+type KeysConfig struct {
+	Active string      `json:"active"`
+	Keys   []KeyConfig `json:"keys"`
+}
+
+type SigningKey struct {
+	Kid       string
+	Algorithm string
+	// signing é nil para chaves que só validam tokens
+	signing      interface{}
+	verification interface{}
+}
+
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet monta o conjunto de chaves a partir da configuração. A chave ativa assina os
+// novos tokens; as demais continuam válidas para verificação durante a rotação.
+func LoadKeySet(cfg KeysConfig) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("nenhuma chave de assinatura configurada")
+	}
+
+	ks := &KeySet{keys: make(map[string]*SigningKey, len(cfg.Keys))}
+	for _, kc := range cfg.Keys {
+		key, err := loadSigningKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("chave %q: %w", kc.Kid, err)
+		}
+		if _, dup := ks.keys[key.Kid]; dup {
+			return nil, fmt.Errorf("kid %q configurado mais de uma vez", key.Kid)
+		}
+		ks.keys[key.Kid] = key
+	}
+
+	active := cfg.Active
+	if active == "" && len(cfg.Keys) == 1 {
+		active = cfg.Keys[0].Kid
+	}
+	ks.active = ks.keys[active]
+	if ks.active == nil {
+		return nil, fmt.Errorf("chave ativa %q não encontrada", active)
+	}
+	if ks.active.signing == nil {
+		return nil, fmt.Errorf("chave ativa %q não possui chave privada", active)
+	}
+
+	return ks, nil
+}
+
+// LoadKeySetFromConfig lê as chaves do arquivo JSON indicado em jwt.keys_file ou, na falta
+// dele, usa o segredo HS256 de jwt.secret. Sem nenhum dos dois, uma chave HS256 aleatória é
+// gerada: os tokens continuam seguros, mas deixam de valer quando o processo reinicia.
+func LoadKeySetFromConfig(cfg JWTConfig) (*KeySet, error) {
+	if cfg.KeysFile != "" {
+		data, err := os.ReadFile(cfg.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		var keys KeysConfig
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.KeysFile, err)
+		}
+		return LoadKeySet(keys)
+	}
+
+	if cfg.Secret != "" {
+		return LoadKeySet(KeysConfig{Keys: []KeyConfig{{Kid: "default", Algorithm: "HS256", Secret: cfg.Secret}}})
+	}
+
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Nenhuma chave JWT configurada, usando uma chave temporária")
+	return LoadKeySet(KeysConfig{Keys: []KeyConfig{{Kid: "temporaria", Algorithm: "HS256", Secret: secret}}})
+}
+
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+func (ks *KeySet) Key(kid string) *SigningKey {
+	return ks.keys[kid]
+}
+
+func loadSigningKey(kc KeyConfig) (*SigningKey, error) {
+	if kc.Kid == "" {
+		return nil, errors.New("kid é obrigatório")
+	}
+	key := &SigningKey{Kid: kc.Kid, Algorithm: kc.Algorithm}
+
+	if key.Algorithm == jwt.SigningMethodHS256.Alg() {
+		secret, err := readSecret(kc.Secret, kc.SecretFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) < 32 {
+			return nil, errors.New("o segredo HS256 deve ter pelo menos 32 bytes")
+		}
+		key.signing = []byte(secret)
+		key.verification = []byte(secret)
+		return key, nil
+	}
+
+	var public crypto.PublicKey
+	switch {
+	case kc.PrivateKeyFile != "":
+		data, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(key.Algorithm, data)
+		if err != nil {
+			return nil, err
+		}
+		key.signing = private
+		public = private.(crypto.Signer).Public()
+	case kc.PublicKeyFile != "":
+		data, err := os.ReadFile(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err = parsePublicKey(key.Algorithm, data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("informe private_key_file ou public_key_file")
+	}
+
+	if err := checkPublicKey(public); err != nil {
+		return nil, err
+	}
+	key.verification = public
+	return key, nil
+}
+
+func readSecret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func parsePrivateKey(alg string, data []byte) (interface{}, error) {
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	case jwt.SigningMethodES256.Alg():
+		return jwt.ParseECPrivateKeyFromPEM(data)
+	case jwt.SigningMethodEdDSA.Alg():
+		return jwt.ParseEdPrivateKeyFromPEM(data)
+	}
+	return nil, fmt.Errorf("algoritmo %q não suportado", alg)
+}
+
+func parsePublicKey(alg string, data []byte) (crypto.PublicKey, error) {
+	switch alg {
+	case jwt.SigningMethodRS256.Alg():
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	case jwt.SigningMethodES256.Alg():
+		return jwt.ParseECPublicKeyFromPEM(data)
+	case jwt.SigningMethodEdDSA.Alg():
+		return jwt.ParseEdPublicKeyFromPEM(data)
+	}
+	return nil, fmt.Errorf("algoritmo %q não suportado", alg)
+}
+
+func checkPublicKey(public crypto.PublicKey) error {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < 2048 {
+			return errors.New("chaves RSA devem ter pelo menos 2048 bits")
+		}
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return errors.New("ES256 exige uma chave na curva P-256")
+		}
+	}
+	return nil
+}
+
+// JWK é a representação pública de uma chave no formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS publica as chaves públicas usadas pela API. Chaves HS256 são simétricas e
+// nunca são publicadas; serviços que validam tokens HS256 precisam do segredo compartilhado.
+func (ks *KeySet) PublicJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		jwk, ok := key.jwk()
+		if ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func (k *SigningKey) jwk() (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: k.Kid, Use: "sig", Alg: k.Algorithm}
+
+	switch pub := k.verification.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ecdh, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, false
+		}
+		// Ponto não comprimido: 0x04 || X || Y, com 32 bytes cada na P-256
+		point := ecdh.Bytes()
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = b64(point[1:33])
+		jwk.Y = b64(point[33:])
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+// Parâmetros fixos da proteção contra força bruta no login. Os limites de falhas e a duração
+// do bloqueio vêm de LoginProtectionConfig.
+const (
+	LoginFalhasSemAtraso = 2
+	LoginAtrasoMaximo    = 30 * time.Second
+	// Falhas mais antigas que a janela deixam de contar para o bloqueio
+	LoginJanelaFalhas = 15 * time.Minute
+)
+
+// Atraso retorna por quanto tempo novas tentativas são recusadas após a falha de número
+// falhas. As primeiras falhas não geram espera, as seguintes dobram a espera até o limite e,
+// ao atingir maxFalhas, o login fica bloqueado por BloqueioDuracao.
+func (c LoginProtectionConfig) Atraso(falhas, maxFalhas int) time.Duration {
+	if falhas >= maxFalhas {
+		return c.BloqueioDuracao.Duration
+	}
+	if falhas <= LoginFalhasSemAtraso {
+		return 0
+	}
+
+	exp := falhas - LoginFalhasSemAtraso - 1
+	if exp >= 16 {
+		return LoginAtrasoMaximo
+	}
+	atraso := time.Second << exp
+	if atraso > LoginAtrasoMaximo {
+		return LoginAtrasoMaximo
+	}
+	return atraso
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"crypto/subtle"
+	"errors"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrSenhaMuitoLonga = errors.New("senha deve ter no máximo 72 bytes")
+
+// dummyHashes guarda, por custo, o hash conferido por DummyCheck. Gerá-lo a cada login
+// inexistente dobraria o tempo da resposta.
+var dummyHashes sync.Map
+
+// Hash gera o hash bcrypt da senha; o salt é aleatório e fica embutido no próprio hash
+func (c PasswordConfig) Hash(senha string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(senha), c.Cost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return "", ErrSenhaMuitoLonga
+		}
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Check compara a senha informada com o valor armazenado em tempo constante.
+// needsRehash indica que o valor armazenado deve ser substituído por um novo hash, seja por
+// ainda estar em texto puro (linhas anteriores ao uso de hash) ou por usar um custo diferente do atual.
+func (c PasswordConfig) Check(stored, senha string) (ok bool, needsRehash bool) {
+	cost, err := bcrypt.Cost([]byte(stored))
+	if err != nil {
+		ok = stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(senha)) == 1
+		return ok, ok
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(senha)) != nil {
+		return false, false
+	}
+	return true, cost != c.Cost
+}
+
+// DummyCheck consome o mesmo tempo de uma verificação real, para que a resposta
+// de um login inexistente não seja mais rápida que a de uma senha incorreta
+func (c PasswordConfig) DummyCheck(senha string) {
+	hash, ok := dummyHashes.Load(c.Cost)
+	if !ok {
+		gerado, _ := bcrypt.GenerateFromPassword([]byte("senha-inexistente"), c.Cost)
+		hash, _ = dummyHashes.LoadOrStore(c.Cost, gerado)
+	}
+	bcrypt.CompareHashAndPassword(hash.([]byte), []byte(senha))
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// RandomId gera um identificador aleatório de 128 bits em hexadecimal
+func RandomId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateOpaqueToken gera um token aleatório de 256 bits, sem significado para o cliente
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaqueToken retorna o hash SHA-256 gravado no banco no lugar do token.
+// Como o token tem alta entropia, um hash rápido sem salt é suficiente.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ApiKeyPrefix identifica as API keys, permitindo distingui-las de um JWT no cabeçalho Authorization
+const ApiKeyPrefix = "gak_"
+
+// GenerateApiKey gera uma API key e o prefixo exibido na listagem para identificá-la
+func GenerateApiKey() (key string, prefixo string, err error) {
+	token, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	key = ApiKeyPrefix + token
+	return key, key[:len(ApiKeyPrefix)+8], nil
+}
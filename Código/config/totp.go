@@ -0,0 +1,109 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parâmetros TOTP (RFC 6238) aceitos por todos os aplicativos autenticadores comuns
+const (
+	TOTPPeriodo = 30
+	TOTPDigitos = 6
+	// Passos aceitos antes e depois do atual, para tolerar relógios levemente dessincronizados
+	TOTPJanela = 1
+)
+
+// TOTPIssuer identifica a API no aplicativo autenticador
+var TOTPIssuer = "go-api"
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret gera um segredo de 160 bits em base32, o formato esperado pelos autenticadores
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI monta a URI otpauth:// usada para gerar o QR code lido pelo autenticador
+func TOTPURI(conta, segredo string) string {
+	params := url.Values{}
+	params.Set("secret", segredo)
+	params.Set("issuer", TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(TOTPDigitos))
+	params.Set("period", fmt.Sprint(TOTPPeriodo))
+
+	label := url.PathEscape(TOTPIssuer) + ":" + url.PathEscape(conta)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPPasso retorna o passo de tempo que contém t
+func TOTPPasso(t time.Time) int64 {
+	return t.Unix() / TOTPPeriodo
+}
+
+// TOTPCode calcula o código do segredo para o passo informado (RFC 4226, seção 5.3)
+func TOTPCode(segredo string, passo int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(segredo))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(passo))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigitos, code%1000000), nil
+}
+
+// ValidateTOTP confere o código dentro da janela de tolerância e retorna o passo em que ele
+// foi gerado, para que o chamador recuse a reutilização do mesmo código
+func ValidateTOTP(segredo, codigo string, t time.Time) (int64, bool) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) != TOTPDigitos {
+		return 0, false
+	}
+
+	atual := TOTPPasso(t)
+	for passo := atual - TOTPJanela; passo <= atual+TOTPJanela; passo++ {
+		esperado, err := TOTPCode(segredo, passo)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(esperado), []byte(codigo)) == 1 {
+			return passo, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCode gera um código de recuperação no formato xxxxx-xxxxx, fácil de digitar
+func GenerateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// NormalizeRecoveryCode remove espaços e hifens e ignora maiúsculas ao comparar códigos de recuperação
+func NormalizeRecoveryCode(codigo string) string {
+	codigo = strings.ToLower(strings.TrimSpace(codigo))
+	return strings.ReplaceAll(strings.ReplaceAll(codigo, "-", ""), " ", "")
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"database/sql"
+	"errors"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ApiKeyController struct {
+	Usecase *usecase.ApiKeyUsecase
+}
+
+func NewApiKeyController(uc *usecase.ApiKeyUsecase) *ApiKeyController {
+	return &ApiKeyController{Usecase: uc}
+}
+
+// @Summary Cria uma API key
+// @Description Cria uma API key com nome, escopos e expiração opcional para o usuário autenticado. A chave é exibida apenas nesta resposta; use-a no cabeçalho X-API-Key ou como token Bearer
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Param apiKey body model.ApiKeyRequest true "Nome, escopos e expiração da chave"
+// @Success 201 {object} model.ApiKeyCreatedResponse
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (c *ApiKeyController) CreateApiKey(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.ApiKeyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Dados inválidos para a API key"})
+		return
+	}
+
+	apiKey, err := c.Usecase.CreateApiKey(caller, request)
+	if err != nil {
+		if errors.Is(err, usecase.ErrEscopoInvalido) || errors.Is(err, usecase.ErrExpiracaoPassada) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível criar a API key"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, apiKey)
+}
+
+// @Summary Lista as API keys
+// @Description Retorna as API keys ativas do usuário autenticado, sem as chaves em si
+// @Tags API keys
+// @Produce json
+// @Success 200 {array} model.ApiKey
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (c *ApiKeyController) GetApiKeys(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	apiKeys, err := c.Usecase.GetApiKeys(caller)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, apiKeys)
+}
+
+// @Summary Revoga uma API key
+// @Description Revoga uma API key do usuário autenticado. A chave deixa de ser aceita imediatamente
+// @Tags API keys
+// @Produce json
+// @Param apiKeyId path int true "ID da API key"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /api-keys/{apiKeyId} [delete]
+func (c *ApiKeyController) RevokeApiKey(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	apiKeyId, err := strconv.Atoi(ctx.Param("apiKeyId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id da API key precisa ser um número"})
+		return
+	}
+
+	if err := c.Usecase.RevokeApiKey(caller, apiKeyId); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "API key não encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: "API key revogada com sucesso"})
+}
@@ -1,9 +1,15 @@
 package controller
 
 import (
+	"database/sql"
+	"errors"
+	"go-api/config"
+	"go-api/middleware"
 	"go-api/model"
 	"go-api/usecase"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,14 +23,18 @@ func NewAuthController(uc *usecase.AuthUsecase) *AuthController {
 }
 
 // @Summary Efetua login
-// @Description Realiza autenticação do usuário e retorna um token JWT
+// @Description Realiza autenticação do usuário e retorna um token JWT de curta duração e um refresh token. Usuários com segundo fator recebem apenas um challenge_token, a ser enviado com o código para /auth/2fa/verify. Falhas seguidas impõem uma espera crescente e, a partir do limite configurado, bloqueiam o login temporariamente. Contas pendentes ou suspensas recebem 403
 // @Tags Autenticação
 // @Accept json
 // @Produce json
 // @Param credentials body model.LoginRequest true "Credenciais do usuário"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} model.TokenResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Header 429 {integer} Retry-After "Segundos até a próxima tentativa ser aceita"
 // @Router /auth/login [post]
 func (c *AuthController) Login(ctx *gin.Context) {
 	var credentials model.LoginRequest
@@ -33,21 +43,354 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := c.Usecase.Login(credentials.Login, credentials.Senha)
+	tokens, err := c.Usecase.Login(credentials.Login, credentials.Senha, origem(ctx))
+	if err != nil {
+		if respondBloqueio(ctx, err) || respondContaInativa(ctx, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrCredenciaisInvalidas) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciais inválidas"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível efetuar o login"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// @Summary Conclui o login com o segundo fator
+// @Description Troca o challenge_token retornado pelo login e um código do autenticador (ou um código de recuperação) pelo par de tokens. Códigos errados contam como falhas de login
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param request body model.DoisFatoresVerifyRequest true "Token de desafio e código"
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Header 429 {integer} Retry-After "Segundos até a próxima tentativa ser aceita"
+// @Router /auth/2fa/verify [post]
+func (c *AuthController) VerifyDoisFatores(ctx *gin.Context) {
+	var request model.DoisFatoresVerifyRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+		return
+	}
+
+	tokens, err := c.Usecase.VerifyDoisFatores(request.ChallengeToken, request.Codigo, origem(ctx))
+	if err != nil {
+		if respondBloqueio(ctx, err) || respondContaInativa(ctx, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrChallengeTokenInvalido) || errors.Is(err, usecase.ErrCodigoDoisFatores) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível efetuar o login"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// respondBloqueio responde 429 com Retry-After quando err é um *usecase.BloqueioError
+func respondBloqueio(ctx *gin.Context, err error) bool {
+	var bloqueio *usecase.BloqueioError
+	if !errors.As(err, &bloqueio) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(bloqueio.RetryAfter.Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "Muitas tentativas de login, tente novamente mais tarde"})
+	return true
+}
+
+// respondContaInativa responde 403 quando o login foi recusado porque a conta está pendente ou suspensa
+func respondContaInativa(ctx *gin.Context, err error) bool {
+	if !errors.Is(err, usecase.ErrContaPendente) && !errors.Is(err, usecase.ErrContaSuspensa) {
+		return false
+	}
+
+	ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	return true
+}
+
+// @Summary Renova o token de acesso
+// @Description Troca um refresh token por um novo par de tokens. Cada refresh token só pode ser usado uma vez; reutilizá-lo revoga toda a sessão
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param refresh body model.RefreshRequest true "Refresh token recebido no login ou na última renovação"
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/refresh [post]
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var request model.RefreshRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+		return
+	}
+
+	tokens, err := c.Usecase.Refresh(request.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciais inválidas"})
+		if errors.Is(err, usecase.ErrRefreshTokenInvalido) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token inválido ou expirado"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível renovar o token"})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"token": token})
+	ctx.JSON(http.StatusOK, tokens)
 }
 
 // @Summary Efetua logout
-// @Description Simula o logout do usuário
+// @Description Revoga o token usado na requisição e os refresh tokens da mesma sessão
 // @Tags Autenticação
 // @Produce json
 // @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
 // @Router /auth/logout [post]
 func (c *AuthController) Logout(ctx *gin.Context) {
+	claims, ok := middleware.GetClaims(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autenticação não informado"})
+		return
+	}
+
+	if err := c.Usecase.Logout(claims); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível efetuar o logout"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"message": "Logout efetuado com sucesso"})
 }
+
+// @Summary Dados do usuário autenticado
+// @Description Retorna o usuário dono do token ou da API key usada na requisição
+// @Tags Autenticação
+// @Produce json
+// @Success 200 {object} model.UsuarioResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /auth/me [get]
+func (c *AuthController) Me(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	usuario, err := c.Usecase.Me(caller.UsuarioId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "Usuário não encontrado"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível consultar o usuário"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewUsuarioResponse(*usuario))
+}
+
+// @Summary Altera os dados do usuário autenticado
+// @Description Altera nome e login do próprio usuário. Campos omitidos são mantidos; senha e papel não mudam por aqui
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param perfil body model.PerfilRequest true "Campos a alterar"
+// @Success 200 {object} model.UsuarioResponse
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /auth/me [patch]
+func (c *AuthController) UpdateMe(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.PerfilRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "JSON inválido"})
+		return
+	}
+
+	usuario, err := c.Usecase.UpdatePerfil(caller.UsuarioId, request)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrPerfilInvalido):
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+		case errors.Is(err, usecase.ErrLoginEmUso):
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "Usuário não encontrado"})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível atualizar o usuário"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewUsuarioResponse(*usuario))
+}
+
+// @Summary Troca a senha do usuário autenticado
+// @Description Exige a senha atual. As demais sessões do usuário são encerradas; a sessão usada na requisição continua válida. Senhas atuais erradas contam como falhas de login
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param request body model.ChangePasswordRequest true "Senha atual e nova senha"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Header 429 {integer} Retry-After "Segundos até a próxima tentativa ser aceita"
+// @Security BearerAuth
+// @Router /auth/change-password [post]
+func (c *AuthController) ChangePassword(ctx *gin.Context) {
+	claims, ok := middleware.GetClaims(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autenticação não informado"})
+		return
+	}
+
+	var request model.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "JSON inválido"})
+		return
+	}
+
+	err := c.Usecase.ChangePassword(claims, request.SenhaAtual, request.NovaSenha, ctx.ClientIP())
+	if err != nil {
+		if respondBloqueio(ctx, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrSenhaAtualIncorreta) || errors.Is(err, config.ErrSenhaMuitoLonga) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível trocar a senha"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: "Senha alterada com sucesso"})
+}
+
+// @Summary Lista as sessões ativas
+// @Description Retorna os logins ativos do usuário autenticado, com dispositivo, IP e último uso. A sessão usada na requisição vem marcada como atual
+// @Tags Autenticação
+// @Produce json
+// @Success 200 {array} model.Sessao
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/sessions [get]
+func (c *AuthController) GetSessoes(ctx *gin.Context) {
+	claims, ok := middleware.GetClaims(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autenticação não informado"})
+		return
+	}
+
+	sessoes, err := c.Usecase.Tokens.GetSessoes(claims.UserId, claims.SessionId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível listar as sessões"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessoes)
+}
+
+// @Summary Encerra uma sessão
+// @Description Encerra remotamente uma sessão do usuário autenticado. Os refresh tokens da sessão são revogados e os tokens de acesso dela deixam de ser aceitos imediatamente
+// @Tags Autenticação
+// @Produce json
+// @Param sessaoId path string true "ID da sessão"
+// @Success 200 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/sessions/{sessaoId} [delete]
+func (c *AuthController) EncerrarSessao(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.Usecase.Tokens.EncerrarSessao(caller.UsuarioId, ctx.Param("sessaoId"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "Sessão não encontrada"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível encerrar a sessão"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: "Sessão encerrada com sucesso"})
+}
+
+// @Summary Chaves públicas de assinatura
+// @Description Publica no formato JWKS as chaves públicas usadas para assinar os tokens, permitindo que outros serviços os validem
+// @Tags Autenticação
+// @Produce json
+// @Success 200 {object} config.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (c *AuthController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.Usecase.Tokens.PublicJWKS())
+}
+
+// @Summary Desbloqueia o login de um usuário
+// @Description Remove o bloqueio temporário e as falhas de login acumuladas pelo usuário. Exige papel admin
+// @Tags Usuarios
+// @Produce json
+// @Param usuarioId path int true "ID do usuário"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /usuario/{usuarioId}/bloqueio [delete]
+func (c *AuthController) DesbloquearUsuario(ctx *gin.Context) {
+	usuarioId, err := strconv.Atoi(ctx.Param("usuarioId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id do Usuário precisa ser um número"})
+		return
+	}
+
+	if err := c.Usecase.DesbloquearUsuario(usuarioId); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "Usuário não encontrado"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: "Usuário desbloqueado com sucesso"})
+}
+
+// origem identifica o cliente da requisição para o registro da sessão e a contagem de falhas de login
+func origem(ctx *gin.Context) model.Origem {
+	return model.Origem{IP: ctx.ClientIP(), UserAgent: ctx.Request.UserAgent()}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DoisFatoresController struct {
+	Usecase *usecase.DoisFatoresUsecase
+}
+
+func NewDoisFatoresController(uc *usecase.DoisFatoresUsecase) *DoisFatoresController {
+	return &DoisFatoresController{Usecase: uc}
+}
+
+// @Summary Inicia o cadastro do segundo fator
+// @Description Gera um segredo TOTP para o usuário autenticado e a URI otpauth:// para o aplicativo autenticador. O segundo fator só passa a ser exigido após a confirmação
+// @Tags Autenticação
+// @Produce json
+// @Success 200 {object} model.DoisFatoresEnrollResponse
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/2fa/enroll [post]
+func (c *DoisFatoresController) Enroll(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := c.Usecase.Enroll(caller.UsuarioId)
+	if err != nil {
+		if errors.Is(err, usecase.ErrDoisFatoresJaAtivo) {
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível iniciar o cadastro do segundo fator"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Confirma o segundo fator
+// @Description Ativa o segundo fator com o primeiro código gerado pelo autenticador e retorna os códigos de recuperação, exibidos apenas uma vez
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param request body model.DoisFatoresCodigoRequest true "Código do autenticador"
+// @Success 200 {object} model.DoisFatoresConfirmResponse
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/2fa/confirm [post]
+func (c *DoisFatoresController) Confirm(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.DoisFatoresCodigoRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.Response{Message: "JSON inválido"})
+		return
+	}
+
+	codigos, err := c.Usecase.Confirm(caller.UsuarioId, request.Codigo)
+	if err != nil {
+		if errors.Is(err, usecase.ErrCodigoDoisFatores) || errors.Is(err, usecase.ErrDoisFatoresNaoIniciado) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrDoisFatoresJaAtivo) {
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível confirmar o segundo fator"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.DoisFatoresConfirmResponse{CodigosRecuperacao: codigos})
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"errors"
+	"go-api/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OidcController struct {
+	Usecase *usecase.OidcUsecase
+}
+
+func NewOidcController(uc *usecase.OidcUsecase) *OidcController {
+	return &OidcController{Usecase: uc}
+}
+
+// @Summary Inicia o login OpenID Connect
+// @Description Redireciona para o provedor de identidade configurado, usando o fluxo authorization code com PKCE
+// @Tags Autenticação
+// @Success 302
+// @Failure 502 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /auth/oidc/login [get]
+func (c *OidcController) Login(ctx *gin.Context) {
+	authURL, err := c.Usecase.Start(ctx.Request.Context())
+	if errors.Is(err, usecase.ErrOidcMuitosLogins) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Provedor de identidade indisponível"})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary Conclui o login OpenID Connect
+// @Description Recebe o retorno do provedor de identidade, valida o ID token e emite os tokens desta API. No primeiro login o usuário é criado com o papel membro
+// @Tags Autenticação
+// @Produce json
+// @Param state query string true "State gerado em /auth/oidc/login"
+// @Param code query string true "Código de autorização"
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/oidc/callback [get]
+func (c *OidcController) Callback(ctx *gin.Context) {
+	// O provedor informa em "error" quando o usuário recusa o login ou algo falha do lado dele
+	if ctx.Query("error") != "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Login recusado pelo provedor de identidade"})
+		return
+	}
+
+	state := ctx.Query("state")
+	code := ctx.Query("code")
+	if state == "" || code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "state e code são obrigatórios"})
+		return
+	}
+
+	response, err := c.Usecase.Callback(ctx.Request.Context(), state, code, origem(ctx))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrOidcStateInvalido):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOidcLoginFalhou):
+			// O erro do provedor fica de fora da resposta
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": usecase.ErrOidcLoginFalhou.Error()})
+		case errors.Is(err, usecase.ErrContaPendente), errors.Is(err, usecase.ErrContaSuspensa):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOidcLoginEmUso):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível concluir o login"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
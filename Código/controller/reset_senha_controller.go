@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"errors"
+	"go-api/config"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResetSenhaController struct {
+	Usecase *usecase.ResetSenhaUsecase
+}
+
+func NewResetSenhaController(uc *usecase.ResetSenhaUsecase) *ResetSenhaController {
+	return &ResetSenhaController{Usecase: uc}
+}
+
+// @Summary Solicita a redefinição de senha
+// @Description Envia ao usuário um token de uso único para redefinir a senha. A resposta é a mesma para logins existentes e inexistentes. Novos pedidos para o mesmo usuário são ignorados por alguns minutos após o envio de um token, que continua valendo
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "Login do usuário"
+// @Success 202 {object} model.Response
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/forgot-password [post]
+func (c *ResetSenhaController) ForgotPassword(ctx *gin.Context) {
+	var request model.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+		return
+	}
+
+	if err := c.Usecase.ForgotPassword(request.Login); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível solicitar a redefinição de senha"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, model.Response{Message: "Se o login existir, as instruções de redefinição de senha serão enviadas"})
+}
+
+// @Summary Redefine a senha
+// @Description Define uma nova senha usando o token recebido em /auth/forgot-password. O token só pode ser usado uma vez e todas as sessões do usuário são encerradas
+// @Tags Autenticação
+// @Accept json
+// @Produce json
+// @Param request body model.ResetPasswordRequest true "Token de redefinição e nova senha"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/reset-password [post]
+func (c *ResetSenhaController) ResetPassword(ctx *gin.Context) {
+	var request model.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "JSON inválido"})
+		return
+	}
+
+	if err := c.Usecase.ResetPassword(request.Token, request.NovaSenha); err != nil {
+		if errors.Is(err, usecase.ErrResetTokenInvalido) || errors.Is(err, config.ErrSenhaMuitoLonga) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Não foi possível redefinir a senha"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: "Senha redefinida com sucesso"})
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SaudeController struct {
+	Usecase *usecase.SaudeUsecase
+}
+
+func NewSaudeController(uc *usecase.SaudeUsecase) *SaudeController {
+	return &SaudeController{Usecase: uc}
+}
+
+// @Summary Verifica se o processo está no ar
+// @Description Responde enquanto o processo atende requisições, sem consultar o banco. Serve para a verificação de vivacidade do orquestrador
+// @Tags Saúde
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (c *SaudeController) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": model.SaudeOk})
+}
+
+// @Summary Verifica se a instância está pronta para receber requisições
+// @Description Consulta o banco com prazo e verifica as migrations e as rotinas em segundo plano. Responde 503 quando algum deles não está ok, para que o balanceador de carga tire a instância de circulação. A resposta traz as estatísticas do pool de conexões, o número de migrations pendentes e a situação das rotinas; os erros do driver e os nomes das migrations vão para o log do servidor
+// @Tags Saúde
+// @Produce json
+// @Success 200 {object} model.Prontidao
+// @Failure 503 {object} model.Prontidao
+// @Router /readyz [get]
+func (c *SaudeController) Readyz(ctx *gin.Context) {
+	prontidao := c.Usecase.Prontidao(ctx.Request.Context())
+	if prontidao.Status != model.SaudeOk {
+		ctx.JSON(http.StatusServiceUnavailable, prontidao)
+		return
+	}
+	ctx.JSON(http.StatusOK, prontidao)
+}
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"database/sql"
+	"errors"
+	"go-api/middleware"
 	"go-api/model"
 	"go-api/usecase"
 	"net/http"
@@ -20,59 +22,101 @@ func NewTarefaController(usecase usecase.TarefaUsecase) TarefaController {
 	}
 }
 
+// requireCaller retorna o usuário autenticado ou responde 401 quando a rota não passou pelo middleware de autenticação
+func requireCaller(ctx *gin.Context) (model.Caller, bool) {
+	c, ok := middleware.GetCaller(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token de autenticação não informado"})
+	}
+	return c, ok
+}
+
 // @Summary Lista todas as tarefas
-// @Description Retorna todas as tarefas cadastradas
+// @Description Retorna todas as tarefas cadastradas para administradores e apenas as do próprio usuário para os demais
 // @Tags Tarefas
 // @Produce json
-// @Success 200 {array} model.Tarefa
+// @Success 200 {array} model.TarefaResponse
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefas [get]
 func (t *TarefaController) GetTarefas(ctx *gin.Context) {
-	tarefas, err := t.tarefaUsecase.GetTarefas()
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	tarefas, err := t.tarefaUsecase.GetTarefas(caller)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, tarefas)
+	ctx.JSON(http.StatusOK, model.NewTarefaResponses(tarefas))
 }
 
 // @Summary Cria uma nova tarefa
-// @Description Cria uma nova tarefa no banco de dados
+// @Description Cria uma nova tarefa no banco de dados. Sem usuário responsável, a tarefa é atribuída a quem a criou; apenas administradores atribuem tarefas a outros usuários
 // @Tags Tarefas
 // @Accept json
 // @Produce json
-// @Param tarefa body model.Tarefa true "Dados da nova tarefa"
-// @Success 201 {object} model.Tarefa
+// @Param tarefa body model.TarefaRequest true "Dados da nova tarefa"
+// @Success 201 {object} model.TarefaResponse
 // @Failure 400 {object} model.Response
+// @Failure 403 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefa [post]
 func (t *TarefaController) CreateTarefa(ctx *gin.Context) {
-	var tarefa model.Tarefa
-	if err := ctx.BindJSON(&tarefa); err != nil {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.TarefaRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	insertedTarefa, err := t.tarefaUsecase.CreateTarefa(tarefa)
+	insertedTarefa, err := t.tarefaUsecase.CreateTarefa(caller, request.ToTarefa())
 	if err != nil {
+		if errors.Is(err, usecase.ErrResponsavelProibido) {
+			ctx.JSON(http.StatusForbidden, model.Response{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrResponsavelInexistente) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, insertedTarefa)
+	ctx.JSON(http.StatusCreated, model.NewTarefaResponse(insertedTarefa))
 }
 
 // @Summary Busca tarefa por ID
-// @Description Retorna os dados de uma tarefa pelo ID
+// @Description Retorna os dados de uma tarefa pelo ID. Tarefas de outros usuários só são visíveis para administradores
 // @Tags Tarefas
 // @Produce json
 // @Param tarefaId path int true "ID da tarefa"
-// @Success 200 {object} model.Tarefa
+// @Success 200 {object} model.TarefaResponse
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefa/{tarefaId} [get]
 func (t *TarefaController) GetTarefaById(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
 	id := ctx.Param("tarefaId")
 	if id == "" {
 		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id da Tarefa não pode ser nulo"})
@@ -85,7 +129,7 @@ func (t *TarefaController) GetTarefaById(ctx *gin.Context) {
 		return
 	}
 
-	tarefa, err := t.tarefaUsecase.GetTarefaById(tarefaId)
+	tarefa, err := t.tarefaUsecase.GetTarefaById(caller, tarefaId)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, err)
 		return
@@ -96,22 +140,31 @@ func (t *TarefaController) GetTarefaById(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, tarefa)
+	ctx.JSON(http.StatusOK, model.NewTarefaResponse(*tarefa))
 }
 
 // @Summary Atualiza tarefa por ID
-// @Description Atualiza os dados de uma tarefa existente
+// @Description Atualiza os dados de uma tarefa existente do próprio usuário, ou de qualquer usuário para administradores
 // @Tags Tarefas
 // @Accept json
 // @Produce json
 // @Param tarefaId path int true "ID da tarefa"
-// @Param tarefa body model.Tarefa true "Novos dados da tarefa"
+// @Param tarefa body model.TarefaRequest true "Novos dados da tarefa"
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
+// @Failure 403 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefa/{tarefaId} [put]
 func (t *TarefaController) UpdateTarefaById(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
 	id := ctx.Param("tarefaId")
 	if id == "" {
 		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id da Tarefa não pode ser nulo"})
@@ -124,18 +177,27 @@ func (t *TarefaController) UpdateTarefaById(ctx *gin.Context) {
 		return
 	}
 
-	var tarefa model.Tarefa
-	if err := ctx.ShouldBindJSON(&tarefa); err != nil {
+	var request model.TarefaRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Dados inválidos para a tarefa"})
 		return
 	}
+	tarefa := request.ToTarefa()
 
-	err = t.tarefaUsecase.UpdateTarefaById(tarefaId, &tarefa)
+	err = t.tarefaUsecase.UpdateTarefaById(caller, tarefaId, &tarefa)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, model.Response{Message: "Tarefa não encontrada"})
 			return
 		}
+		if errors.Is(err, usecase.ErrResponsavelProibido) {
+			ctx.JSON(http.StatusForbidden, model.Response{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrResponsavelInexistente) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,8 +214,16 @@ func (t *TarefaController) UpdateTarefaById(ctx *gin.Context) {
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefa/{tarefaId} [delete]
 func (t *TarefaController) SoftDeleteTarefaById(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
 	id := ctx.Param("tarefaId")
 	if id == "" {
 		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id da Tarefa não pode ser nulo"})
@@ -166,7 +236,7 @@ func (t *TarefaController) SoftDeleteTarefaById(ctx *gin.Context) {
 		return
 	}
 
-	err = t.tarefaUsecase.SoftDeleteTarefaById(tarefaId)
+	err = t.tarefaUsecase.SoftDeleteTarefaById(caller, tarefaId)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, model.Response{Message: "Tarefa não encontrada ou já deletada"})
@@ -180,26 +250,39 @@ func (t *TarefaController) SoftDeleteTarefaById(ctx *gin.Context) {
 }
 
 // @Summary Lista tarefas por usuário
-// @Description Retorna todas as tarefas de um usuário específico
+// @Description Retorna todas as tarefas de um usuário específico. Usuários comuns só consultam as próprias tarefas
 // @Tags Tarefas
 // @Produce json
 // @Param usuarioId path string true "ID do usuário"
-// @Success 200 {array} model.Tarefa
+// @Success 200 {array} model.TarefaResponse
 // @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /tarefausuario/{usuarioId} [get]
 func (t *TarefaController) GetTarefasByUsuarioId(ctx *gin.Context) {
+	caller, ok := requireCaller(ctx)
+	if !ok {
+		return
+	}
+
 	usuarioId := ctx.Param("usuarioId")
 	if usuarioId == "" {
 		ctx.JSON(http.StatusBadRequest, model.Response{Message: "Id do Usuário não pode ser nulo"})
 		return
 	}
 
-	tarefas, err := t.tarefaUsecase.GetTarefasByUsuarioId(usuarioId)
+	tarefas, err := t.tarefaUsecase.GetTarefasByUsuarioId(caller, usuarioId)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, model.Response{Message: "Usuário não encontrado"})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, tarefas)
+	ctx.JSON(http.StatusOK, model.NewTarefaResponses(tarefas))
 }
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"database/sql"
+	"errors"
+	"go-api/config"
 	"go-api/model"
 	"go-api/usecase"
 	"net/http"
@@ -22,11 +24,15 @@ func NewUsuarioController(usecase usecase.UsuarioUsecase) usuarioController {
 }
 
 // @Summary Lista todos os usuários
-// @Description Retorna todos os usuários registrados
+// @Description Retorna todos os usuários registrados, exceto os desativados. Exige papel admin
 // @Tags Usuarios
 // @Produce json
-// @Success 200 {array} model.Usuario
+// @Success 200 {array} model.UsuarioResponse
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /usuarios [get]
 func (u *usuarioController) GetUsuarios(ctx *gin.Context) {
 	usuarios, err := u.usuarioUsecase.GetUsuarios()
@@ -34,42 +40,59 @@ func (u *usuarioController) GetUsuarios(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, usuarios)
+	ctx.JSON(http.StatusOK, model.NewUsuarioResponses(usuarios))
 }
 
 // @Summary Cria um novo usuário
-// @Description Cria um novo usuário no banco de dados
+// @Description Cria um novo usuário no banco de dados. Exige papel admin; sem papel informado o usuário é criado como membro e, sem status, já ativo. O status inicial pode ser ativo ou pendente
 // @Tags Usuarios
 // @Accept json
 // @Produce json
-// @Param usuario body model.Usuario true "Dados do novo usuário"
-// @Success 201 {object} model.Usuario
+// @Param usuario body model.UsuarioRequest true "Dados do novo usuário"
+// @Success 201 {object} model.UsuarioResponse
 // @Failure 400 {object} model.Response
+// @Failure 409 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /usuario [post]
 func (u *usuarioController) CreateUsuario(ctx *gin.Context) {
-	var usuario model.Usuario
-	if err := ctx.BindJSON(&usuario); err != nil {
+	var request model.UsuarioRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	insertedUsuario, err := u.usuarioUsecase.CreateUsuario(usuario)
+	insertedUsuario, err := u.usuarioUsecase.CreateUsuario(request.ToUsuario())
 	if err != nil {
+		if errors.Is(err, config.ErrSenhaMuitoLonga) || errors.Is(err, usecase.ErrPapelInvalido) || errors.Is(err, usecase.ErrStatusInvalido) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrLoginEmUso) {
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusCreated, insertedUsuario)
+	ctx.JSON(http.StatusCreated, model.NewUsuarioResponse(insertedUsuario))
 }
 
 // @Summary Busca usuário por ID
-// @Description Retorna os dados de um usuário pelo ID
+// @Description Retorna os dados de um usuário pelo ID. Membros só podem consultar a si mesmos
 // @Tags Usuarios
 // @Produce json
 // @Param usuarioId path int true "ID do usuário"
-// @Success 200 {object} model.Usuario
+// @Success 200 {object} model.UsuarioResponse
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /usuario/{usuarioId} [get]
 func (u *usuarioController) GetUsuarioById(ctx *gin.Context) {
 	id := ctx.Param("usuarioId")
@@ -94,20 +117,25 @@ func (u *usuarioController) GetUsuarioById(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, response)
 		return
 	}
-	ctx.JSON(http.StatusOK, usuario)
+	ctx.JSON(http.StatusOK, model.NewUsuarioResponse(*usuario))
 }
 
 // @Summary Atualiza usuário por ID
-// @Description Atualiza os dados de um usuário existente
+// @Description Atualiza o nome e o login de um usuário existente. O papel muda por /usuario/{usuarioId}/papel e a senha por /auth/change-password. Membros só podem atualizar a si mesmos
 // @Tags Usuarios
 // @Accept json
 // @Produce json
 // @Param usuarioId path int true "ID do usuário"
-// @Param usuario body model.Usuario true "Novos dados do usuário"
+// @Param usuario body model.UsuarioUpdateRequest true "Novos dados do usuário"
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /usuario/{usuarioId} [put]
 func (u *usuarioController) UpdateUsuarioById(ctx *gin.Context) {
 	id := ctx.Param("usuarioId")
@@ -122,14 +150,19 @@ func (u *usuarioController) UpdateUsuarioById(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
-	var usuario model.Usuario
-	if err := ctx.ShouldBindJSON(&usuario); err != nil {
+	var request model.UsuarioUpdateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
 		response := model.Response{Message: "Dados inválidos para o usuário"}
 		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
+	usuario := request.ToUsuario()
 	err = u.usuarioUsecase.UpdateUsuarioById(usuarioId, &usuario)
 	if err != nil {
+		if errors.Is(err, usecase.ErrLoginEmUso) {
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+			return
+		}
 		if err == sql.ErrNoRows {
 			response := model.Response{Message: "Usuario não encontrado"}
 			ctx.JSON(http.StatusNotFound, response)
@@ -143,7 +176,7 @@ func (u *usuarioController) UpdateUsuarioById(ctx *gin.Context) {
 }
 
 // @Summary Deleta (soft delete) um usuário por ID
-// @Description Marca o usuário como inativo em vez de remover do banco
+// @Description Desativa o usuário em vez de remover do banco e encerra todas as suas sessões. Membros só podem remover a si mesmos
 // @Tags Usuarios
 // @Produce json
 // @Param usuarioId path int true "ID do usuário"
@@ -151,6 +184,10 @@ func (u *usuarioController) UpdateUsuarioById(ctx *gin.Context) {
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /usuario/{usuarioId} [delete]
 func (u *usuarioController) SoftDeleteUsuarioById(ctx *gin.Context) {
 	id := ctx.Param("usuarioId")
@@ -178,3 +215,102 @@ func (u *usuarioController) SoftDeleteUsuarioById(ctx *gin.Context) {
 	response := model.Response{Message: "Usuário deletado com sucesso"}
 	ctx.JSON(http.StatusOK, response)
 }
+
+// @Summary Altera o papel de um usuário
+// @Description Define o papel (admin ou membro) de um usuário e encerra todas as suas sessões, para que o papel anterior deixe de valer imediatamente. Exige papel admin
+// @Tags Usuarios
+// @Accept json
+// @Produce json
+// @Param usuarioId path int true "ID do usuário"
+// @Param papel body model.PapelRequest true "Novo papel do usuário"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /usuario/{usuarioId}/papel [put]
+func (u *usuarioController) UpdatePapelById(ctx *gin.Context) {
+	usuarioId, err := strconv.Atoi(ctx.Param("usuarioId"))
+	if err != nil {
+		response := model.Response{Message: "Id do Usuario precisa ser um numero"}
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+	var request model.PapelRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		response := model.Response{Message: "Papel do usuário não informado"}
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+	err = u.usuarioUsecase.UpdatePapelById(usuarioId, request.Papel)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPapelInvalido) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		if err == sql.ErrNoRows {
+			response := model.Response{Message: "Usuario não encontrado"}
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := model.Response{Message: "Papel do usuário atualizado com sucesso"}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Altera o status de um usuário
+// @Description Move a conta pelo ciclo de vida (pendente, ativo, suspenso, desativado). Apenas contas ativas fazem login; ao sair do estado ativo, todas as sessões do usuário são encerradas. Exige papel admin
+// @Tags Usuarios
+// @Accept json
+// @Produce json
+// @Param usuarioId path int true "ID do usuário"
+// @Param status body model.StatusRequest true "Novo status do usuário"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /usuario/{usuarioId}/status [put]
+func (u *usuarioController) UpdateStatusById(ctx *gin.Context) {
+	usuarioId, err := strconv.Atoi(ctx.Param("usuarioId"))
+	if err != nil {
+		response := model.Response{Message: "Id do Usuario precisa ser um numero"}
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+	var request model.StatusRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		response := model.Response{Message: "Status do usuário não informado"}
+		ctx.JSON(http.StatusBadRequest, response)
+		return
+	}
+	err = u.usuarioUsecase.UpdateStatusById(usuarioId, request.Status)
+	if err != nil {
+		if errors.Is(err, usecase.ErrStatusInvalido) {
+			ctx.JSON(http.StatusBadRequest, model.Response{Message: err.Error()})
+			return
+		}
+		if errors.Is(err, usecase.ErrTransicaoInvalida) {
+			ctx.JSON(http.StatusConflict, model.Response{Message: err.Error()})
+			return
+		}
+		if err == sql.ErrNoRows {
+			response := model.Response{Message: "Usuario não encontrado"}
+			ctx.JSON(http.StatusNotFound, response)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	response := model.Response{Message: "Status do usuário atualizado com sucesso"}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -1,34 +1,138 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net"
+	"strconv"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"go-api/config"
+
+	"github.com/go-sql-driver/mysql"
 )
 
-const (
-	host     = "localhost"
-	port     = 3306
-	user     = "master"
-	password = "yw2qq7tf"
-	dbname   = "trabgb"
+// Intervalo entre as tentativas de conexão na inicialização. A espera dobra a cada falha até o máximo.
+var (
+	RetryInicial = 500 * time.Millisecond
+	RetryMaximo  = 10 * time.Second
 )
 
-func ConnectDB() (*sql.DB, error) {
-	// MySQL DSN format
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", user, password, host, port, dbname)
+// ConnectDB conecta ao banco descrito em cfg, tentando novamente até database.connect_timeout.
+// O backend é escolhido por database.driver; os repositórios usam apenas SQL comum a todos,
+// de modo que as diferenças entre eles ficam concentradas aqui e na tradução de parâmetros
+// feita pelos repositórios para o PostgreSQL.
+func ConnectDB(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	switch cfg.Driver {
+	case config.DriverMySQL:
+		return connectMySQL(ctx, cfg)
+	case config.DriverSQLite:
+		return connectSQLite(ctx, cfg)
+	case config.DriverPostgres:
+		return connectPostgres(ctx, cfg)
+	case config.DriverMemory:
+		cfg.Path = ":memory:"
+		return connectSQLite(ctx, cfg)
+	}
+	return nil, fmt.Errorf("driver de banco de dados %q não suportado", cfg.Driver)
+}
 
-	db, err := sql.Open("mysql", dsn)
+func connectMySQL(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	mysqlCfg := mysql.NewConfig()
+	mysqlCfg.User = cfg.User
+	mysqlCfg.Passwd = cfg.Password
+	mysqlCfg.Net = "tcp"
+	mysqlCfg.Addr = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	mysqlCfg.DBName = cfg.Name
+	mysqlCfg.ParseTime = true
+	// Sem isso o MySQL conta só as linhas alteradas, e um UPDATE que regrava os mesmos valores
+	// seria tratado pelos repositórios como registro inexistente
+	mysqlCfg.ClientFoundRows = true
+	// Sem limites, uma requisição fica presa enquanto o banco não responde
+	mysqlCfg.Timeout = 5 * time.Second
+	mysqlCfg.ReadTimeout = 30 * time.Second
+	mysqlCfg.WriteTimeout = 30 * time.Second
+
+	db, err := Connect(ctx, "mysql", mysqlCfg.FormatDSN(), cfg)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	fmt.Println("Connected to", cfg.Name)
+	return db, nil
+}
 
-	err = db.Ping()
+// Connect abre o pool com as configurações de cfg e espera o banco responder. Enquanto o
+// banco estiver fora do ar, novas tentativas são feitas com espera exponencial até o prazo
+// de cfg.ConnectTimeout ou o cancelamento de ctx.
+func Connect(ctx context.Context, driverName, dsn string, cfg config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
 
-	fmt.Println("Connected to", dbname)
-	return db, nil
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout.Duration)
+	defer cancel()
+
+	espera := RetryInicial
+	var ultimoErr error
+	for tentativa := 1; ; tentativa++ {
+		err = db.PingContext(ctx)
+		if err == nil {
+			return db, nil
+		}
+		// Um ping interrompido pelo prazo só diria "context deadline exceeded"; o erro da
+		// tentativa anterior explica melhor por que o banco não respondeu
+		if ctx.Err() == nil || ultimoErr == nil {
+			ultimoErr = err
+		}
+		fmt.Printf("Banco de dados indisponível (tentativa %d): %v\n", tentativa, err)
+
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, fmt.Errorf("banco de dados indisponível após %d tentativas: %w", tentativa, ultimoErr)
+		case <-time.After(espera):
+		}
+
+		espera *= 2
+		if espera > RetryMaximo {
+			espera = RetryMaximo
+		}
+	}
+}
+
+// Monitor verifica a conexão periodicamente até ctx ser cancelado e registra quando o banco
+// cai e quando volta. O pool descarta as conexões quebradas e abre novas sozinho; o ping
+// periódico apenas antecipa essa troca para que a primeira requisição após a queda não falhe.
+func Monitor(ctx context.Context, db *sql.DB, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	disponivel := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, intervalo)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil && disponivel:
+			fmt.Println("Conexão com o banco de dados perdida:", err)
+		case err == nil && !disponivel:
+			fmt.Println("Conexão com o banco de dados restabelecida")
+		}
+		disponivel = err == nil
+	}
 }
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"go-api/config"
+
+	_ "github.com/lib/pq"
+)
+
+// connectPostgres conecta ao PostgreSQL descrito em cfg. As tabelas são criadas pelas migrations.
+func connectPostgres(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("sslmode", cfg.SSLMode)
+	// Sem limite, a inicialização fica presa enquanto o banco não responde
+	params.Set("connect_timeout", "5")
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Name,
+		RawQuery: params.Encode(),
+	}
+
+	db, err := Connect(ctx, "postgres", dsn.String(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Connected to", cfg.Name)
+	return db, nil
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"go-api/config"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// connectSQLite abre o banco SQLite em cfg.Path. As tabelas são criadas pelas migrations.
+func connectSQLite(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	if cfg.Driver == config.DriverMemory {
+		// Com o driver memory os usuários não estão no banco, e as chaves estrangeiras que os
+		// referenciam recusariam qualquer gravação
+		params.Set("_foreign_keys", "off")
+	}
+	params.Set("_busy_timeout", "5000")
+	// As datas são gravadas com o fuso de quem as gerou e lidas no fuso local
+	params.Set("_loc", "auto")
+
+	var dsn string
+	if cfg.Path == ":memory:" {
+		// Cada conexão a ":memory:" teria um banco próprio e o banco some quando a conexão
+		// fecha. O pool fica com uma única conexão, mantida aberta enquanto o processo durar.
+		dsn = "file::memory:?" + params.Encode()
+		cfg.MaxOpenConns = 1
+		cfg.MaxIdleConns = 1
+		cfg.ConnMaxLifetime = config.Duration{}
+	} else {
+		params.Set("_journal_mode", "WAL")
+		params.Set("_txlock", "immediate")
+		dsn = "file:" + cfg.Path + "?" + params.Encode()
+	}
+
+	db, err := Connect(ctx, "sqlite3", dsn, cfg)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println("Connected to", cfg.Path)
+	return db, nil
+}
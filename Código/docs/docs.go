@@ -24,42 +24,53 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Realiza autenticação do usuário e retorna um token JWT",
-                "consumes": [
-                    "application/json"
-                ],
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Publica no formato JWKS as chaves públicas usadas para assinar os tokens, permitindo que outros serviços os validem",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Autenticação"
                 ],
-                "summary": "Efetua login",
-                "parameters": [
-                    {
-                        "description": "Credenciais do usuário",
-                        "name": "credentials",
-                        "in": "body",
-                        "required": true,
+                "summary": "Chaves públicas de assinatura",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.LoginRequest"
+                            "$ref": "#/definitions/config.JWKSet"
                         }
                     }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna as API keys ativas do usuário autenticado, sem as chaves em si",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "API keys"
                 ],
+                "summary": "Lista as API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ApiKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -67,31 +78,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "description": "Simula o logout do usuário",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Autenticação"
-                ],
-                "summary": "Efetua logout",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -100,11 +97,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tarefa": {
+            },
             "post": {
-                "description": "Cria uma nova tarefa no banco de dados",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Cria uma API key com nome, escopos e expiração opcional para o usuário autenticado. A chave é exibida apenas nesta resposta; use-a no cabeçalho X-API-Key ou como token Bearer",
                 "consumes": [
                     "application/json"
                 ],
@@ -112,17 +112,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tarefas"
+                    "API keys"
                 ],
-                "summary": "Cria uma nova tarefa",
+                "summary": "Cria uma API key",
                 "parameters": [
                     {
-                        "description": "Dados da nova tarefa",
-                        "name": "tarefa",
+                        "description": "Nome, escopos e expiração da chave",
+                        "name": "apiKey",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Tarefa"
+                            "$ref": "#/definitions/model.ApiKeyRequest"
                         }
                     }
                 ],
@@ -130,7 +130,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.Tarefa"
+                            "$ref": "#/definitions/model.ApiKeyCreatedResponse"
                         }
                     },
                     "400": {
@@ -139,30 +139,56 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/tarefa/{tarefaId}": {
-            "get": {
-                "description": "Retorna os dados de uma tarefa pelo ID",
+        "/api-keys/{apiKeyId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoga uma API key do usuário autenticado. A chave deixa de ser aceita imediatamente",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tarefas"
+                    "API keys"
                 ],
-                "summary": "Busca tarefa por ID",
+                "summary": "Revoga uma API key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID da tarefa",
-                        "name": "tarefaId",
+                        "description": "ID da API key",
+                        "name": "apiKeyId",
                         "in": "path",
                         "required": true
                     }
@@ -171,7 +197,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Tarefa"
+                            "$ref": "#/definitions/model.Response"
                         }
                     },
                     "400": {
@@ -180,6 +206,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -189,13 +233,23 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza os dados de uma tarefa existente",
+            }
+        },
+        "/auth/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ativa o segundo fator com o primeiro código gerado pelo autenticador e retorna os códigos de recuperação, exibidos apenas uma vez",
                 "consumes": [
                     "application/json"
                 ],
@@ -203,24 +257,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Tarefas"
+                    "Autenticação"
                 ],
-                "summary": "Atualiza tarefa por ID",
+                "summary": "Confirma o segundo fator",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID da tarefa",
-                        "name": "tarefaId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Novos dados da tarefa",
-                        "name": "tarefa",
+                        "description": "Código do autenticador",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Tarefa"
+                            "$ref": "#/definitions/model.DoisFatoresCodigoRequest"
                         }
                     }
                 ],
@@ -228,7 +275,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.DoisFatoresConfirmResponse"
                         }
                     },
                     "400": {
@@ -237,8 +284,17 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -246,44 +302,48 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Marca a tarefa como inativa em vez de removê-la do banco",
+            }
+        },
+        "/auth/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gera um segredo TOTP para o usuário autenticado e a URI otpauth:// para o aplicativo autenticador. O segundo fator só passa a ser exigido após a confirmação",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tarefas"
-                ],
-                "summary": "Deleta (soft delete) uma tarefa por ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID da tarefa",
-                        "name": "tarefaId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Autenticação"
                 ],
+                "summary": "Inicia o cadastro do segundo fator",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.DoisFatoresEnrollResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -291,88 +351,108 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/tarefas": {
-            "get": {
-                "description": "Retorna todas as tarefas cadastradas",
-                "produces": [
+        "/auth/2fa/verify": {
+            "post": {
+                "description": "Troca o challenge_token retornado pelo login e um código do autenticador (ou um código de recuperação) pelo par de tokens. Códigos errados contam como falhas de login",
+                "consumes": [
                     "application/json"
                 ],
-                "tags": [
-                    "Tarefas"
-                ],
-                "summary": "Lista todas as tarefas",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Tarefa"
-                            }
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/tarefausuario/{usuarioId}": {
-            "get": {
-                "description": "Retorna todas as tarefas de um usuário específico",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Tarefas"
+                    "Autenticação"
                 ],
-                "summary": "Lista tarefas por usuário",
+                "summary": "Conclui o login com o segundo fator",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID do usuário",
-                        "name": "usuarioId",
-                        "in": "path",
-                        "required": true
+                        "description": "Token de desafio e código",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DoisFatoresVerifyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Tarefa"
-                            }
+                            "$ref": "#/definitions/model.TokenResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
-                    }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Segundos até a próxima tentativa ser aceita"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "/usuario": {
+        "/auth/change-password": {
             "post": {
-                "description": "Cria um novo usuário no banco de dados",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exige a senha atual. As demais sessões do usuário são encerradas; a sessão usada na requisição continua válida. Senhas atuais erradas contam como falhas de login",
                 "consumes": [
                     "application/json"
                 ],
@@ -380,25 +460,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Usuarios"
+                    "Autenticação"
                 ],
-                "summary": "Cria um novo usuário",
+                "summary": "Troca a senha do usuário autenticado",
                 "parameters": [
                     {
-                        "description": "Dados do novo usuário",
-                        "name": "usuario",
+                        "description": "Senha atual e nova senha",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Usuario"
+                            "$ref": "#/definitions/model.ChangePasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Usuario"
+                            "$ref": "#/definitions/model.Response"
                         }
                     },
                     "400": {
@@ -407,63 +487,97 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Segundos até a próxima tentativa ser aceita"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/usuario/{usuarioId}": {
-            "get": {
-                "description": "Retorna os dados de um usuário pelo ID",
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Envia ao usuário um token de uso único para redefinir a senha. A resposta é a mesma para logins existentes e inexistentes. Novos pedidos para o mesmo usuário são ignorados por alguns minutos após o envio de um token, que continua valendo",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Usuarios"
+                    "Autenticação"
                 ],
-                "summary": "Busca usuário por ID",
+                "summary": "Solicita a redefinição de senha",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do usuário",
-                        "name": "usuarioId",
-                        "in": "path",
-                        "required": true
+                        "description": "Login do usuário",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/model.Usuario"
+                            "$ref": "#/definitions/model.Response"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Atualiza os dados de um usuário existente",
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Realiza autenticação do usuário e retorna um token JWT de curta duração e um refresh token. Usuários com segundo fator recebem apenas um challenge_token, a ser enviado com o código para /auth/2fa/verify. Falhas seguidas impõem uma espera crescente e, a partir do limite configurado, bloqueiam o login temporariamente. Contas pendentes ou suspensas recebem 403",
                 "consumes": [
                     "application/json"
                 ],
@@ -471,24 +585,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Usuarios"
+                    "Autenticação"
                 ],
-                "summary": "Atualiza usuário por ID",
+                "summary": "Efetua login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "ID do usuário",
-                        "name": "usuarioId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Novos dados do usuário",
-                        "name": "usuario",
+                        "description": "Credenciais do usuário",
+                        "name": "credentials",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Usuario"
+                            "$ref": "#/definitions/model.LoginRequest"
                         }
                     }
                 ],
@@ -496,164 +603,2216 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TokenResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Segundos até a próxima tentativa ser aceita"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Marca o usuário como inativo em vez de remover do banco",
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoga o token usado na requisição e os refresh tokens da mesma sessão",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Usuarios"
-                ],
-                "summary": "Deleta (soft delete) um usuário por ID",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "ID do usuário",
-                        "name": "usuarioId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Autenticação"
                 ],
+                "summary": "Efetua logout",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/usuarios": {
+        "/auth/me": {
             "get": {
-                "description": "Retorna todos os usuários registrados",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna o usuário dono do token ou da API key usada na requisição",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Usuarios"
+                    "Autenticação"
                 ],
-                "summary": "Lista todos os usuários",
+                "summary": "Dados do usuário autenticado",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Usuario"
+                            "$ref": "#/definitions/model.UsuarioResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "model.LoginRequest": {
-            "type": "object",
-            "properties": {
-                "login": {
-                    "type": "string",
-                    "example": "usuario123"
-                },
-                "senha": {
-                    "type": "string",
-                    "example": "senhaSegura"
-                }
-            }
-        },
-        "model.Response": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Altera nome e login do próprio usuário. Campos omitidos são mantidos; senha e papel não mudam por aqui",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Altera os dados do usuário autenticado",
+                "parameters": [
+                    {
+                        "description": "Campos a alterar",
+                        "name": "perfil",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PerfilRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsuarioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Recebe o retorno do provedor de identidade, valida o ID token e emite os tokens desta API. No primeiro login o usuário é criado com o papel membro",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Conclui o login OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "State gerado em /auth/oidc/login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Código de autorização",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redireciona para o provedor de identidade configurado, usando o fluxo authorization code com PKCE",
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Inicia o login OpenID Connect",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Troca um refresh token por um novo par de tokens. Cada refresh token só pode ser usado uma vez; reutilizá-lo revoga toda a sessão",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Renova o token de acesso",
+                "parameters": [
+                    {
+                        "description": "Refresh token recebido no login ou na última renovação",
+                        "name": "refresh",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Define uma nova senha usando o token recebido em /auth/forgot-password. O token só pode ser usado uma vez e todas as sessões do usuário são encerradas",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Redefine a senha",
+                "parameters": [
+                    {
+                        "description": "Token de redefinição e nova senha",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retorna os logins ativos do usuário autenticado, com dispositivo, IP e último uso. A sessão usada na requisição vem marcada como atual",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Lista as sessões ativas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Sessao"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{sessaoId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Encerra remotamente uma sessão do usuário autenticado. Os refresh tokens da sessão são revogados e os tokens de acesso dela deixam de ser aceitos imediatamente",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Autenticação"
+                ],
+                "summary": "Encerra uma sessão",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID da sessão",
+                        "name": "sessaoId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Responde enquanto o processo atende requisições, sem consultar o banco. Serve para a verificação de vivacidade do orquestrador",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saúde"
+                ],
+                "summary": "Verifica se o processo está no ar",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Consulta o banco com prazo e verifica as migrations e as rotinas em segundo plano. Responde 503 quando algum deles não está ok, para que o balanceador de carga tire a instância de circulação. A resposta traz as estatísticas do pool de conexões, o número de migrations pendentes e a situação das rotinas; os erros do driver e os nomes das migrations vão para o log do servidor",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Saúde"
+                ],
+                "summary": "Verifica se a instância está pronta para receber requisições",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Prontidao"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Prontidao"
+                        }
+                    }
+                }
+            }
+        },
+        "/tarefa": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria uma nova tarefa no banco de dados. Sem usuário responsável, a tarefa é atribuída a quem a criou; apenas administradores atribuem tarefas a outros usuários",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Cria uma nova tarefa",
+                "parameters": [
+                    {
+                        "description": "Dados da nova tarefa",
+                        "name": "tarefa",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TarefaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.TarefaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tarefa/{tarefaId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna os dados de uma tarefa pelo ID. Tarefas de outros usuários só são visíveis para administradores",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Busca tarefa por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da tarefa",
+                        "name": "tarefaId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.TarefaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza os dados de uma tarefa existente do próprio usuário, ou de qualquer usuário para administradores",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Atualiza tarefa por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da tarefa",
+                        "name": "tarefaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novos dados da tarefa",
+                        "name": "tarefa",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.TarefaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marca a tarefa como inativa em vez de removê-la do banco",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Deleta (soft delete) uma tarefa por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID da tarefa",
+                        "name": "tarefaId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tarefas": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todas as tarefas cadastradas para administradores e apenas as do próprio usuário para os demais",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Lista todas as tarefas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TarefaResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tarefausuario/{usuarioId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todas as tarefas de um usuário específico. Usuários comuns só consultam as próprias tarefas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tarefas"
+                ],
+                "summary": "Lista tarefas por usuário",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TarefaResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/usuario": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cria um novo usuário no banco de dados. Exige papel admin; sem papel informado o usuário é criado como membro e, sem status, já ativo. O status inicial pode ser ativo ou pendente",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Cria um novo usuário",
+                "parameters": [
+                    {
+                        "description": "Dados do novo usuário",
+                        "name": "usuario",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UsuarioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsuarioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/usuario/{usuarioId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna os dados de um usuário pelo ID. Membros só podem consultar a si mesmos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Busca usuário por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.UsuarioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Atualiza o nome e o login de um usuário existente. O papel muda por /usuario/{usuarioId}/papel e a senha por /auth/change-password. Membros só podem atualizar a si mesmos",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Atualiza usuário por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novos dados do usuário",
+                        "name": "usuario",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UsuarioUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Desativa o usuário em vez de remover do banco e encerra todas as suas sessões. Membros só podem remover a si mesmos",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Deleta (soft delete) um usuário por ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/usuario/{usuarioId}/bloqueio": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove o bloqueio temporário e as falhas de login acumuladas pelo usuário. Exige papel admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Desbloqueia o login de um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/usuario/{usuarioId}/papel": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Define o papel (admin ou membro) de um usuário e encerra todas as suas sessões, para que o papel anterior deixe de valer imediatamente. Exige papel admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Altera o papel de um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo papel do usuário",
+                        "name": "papel",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PapelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/usuario/{usuarioId}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move a conta pelo ciclo de vida (pendente, ativo, suspenso, desativado). Apenas contas ativas fazem login; ao sair do estado ativo, todas as sessões do usuário são encerradas. Exige papel admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Altera o status de um usuário",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID do usuário",
+                        "name": "usuarioId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Novo status do usuário",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/usuarios": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retorna todos os usuários registrados, exceto os desativados. Exige papel admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Usuarios"
+                ],
+                "summary": "Lista todos os usuários",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.UsuarioResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "config.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "config.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/config.JWK"
+                    }
+                }
+            }
+        },
+        "model.ApiKey": {
+            "type": "object",
+            "properties": {
+                "criada_em": {
+                    "type": "string"
+                },
+                "escopos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tarefas:read",
+                        "tarefas:write"
+                    ]
+                },
+                "expira_em": {
+                    "type": "string"
+                },
+                "id_api_key": {
+                    "type": "integer"
+                },
+                "nome": {
+                    "type": "string",
+                    "example": "deploy-ci"
+                },
+                "prefixo": {
+                    "type": "string",
+                    "example": "gak_3q2x7wEa"
+                },
+                "ultimo_uso": {
+                    "type": "string"
+                },
+                "usuario_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ApiKeyCreatedResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "gak_3q2x7wEa..."
+                },
+                "criada_em": {
+                    "type": "string"
+                },
+                "escopos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tarefas:read",
+                        "tarefas:write"
+                    ]
+                },
+                "expira_em": {
+                    "type": "string"
+                },
+                "id_api_key": {
+                    "type": "integer"
+                },
+                "nome": {
+                    "type": "string",
+                    "example": "deploy-ci"
+                },
+                "prefixo": {
+                    "type": "string",
+                    "example": "gak_3q2x7wEa"
+                },
+                "ultimo_uso": {
+                    "type": "string"
+                },
+                "usuario_id": {
+                    "type": "integer"
+                }
+            }
         },
-        "model.Tarefa": {
+        "model.ApiKeyRequest": {
             "type": "object",
+            "required": [
+                "escopos",
+                "nome"
+            ],
             "properties": {
-                "conteudo_tarefa": {
+                "escopos": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tarefas:read",
+                        "tarefas:write"
+                    ]
+                },
+                "expira_em": {
+                    "type": "string",
+                    "example": "2026-12-31T23:59:59Z"
+                },
+                "nome": {
+                    "type": "string",
+                    "example": "deploy-ci"
+                }
+            }
+        },
+        "model.BancoResumo": {
+            "type": "object",
+            "properties": {
+                "latencia_ms": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "pool": {
+                    "$ref": "#/definitions/model.PoolStatus"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "model.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "nova_senha",
+                "senha_atual"
+            ],
+            "properties": {
+                "nova_senha": {
                     "type": "string"
                 },
-                "finalizado": {
+                "senha_atual": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DoisFatoresCodigoRequest": {
+            "type": "object",
+            "required": [
+                "codigo"
+            ],
+            "properties": {
+                "codigo": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "model.DoisFatoresConfirmResponse": {
+            "type": "object",
+            "properties": {
+                "codigos_recuperacao": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "model.DoisFatoresEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/go-api:usuario123?secret=JBSWY3DPEHPK3PXP\u0026issuer=go-api"
+                },
+                "segredo": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "model.DoisFatoresVerifyRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "codigo"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "codigo": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "model.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "login"
+            ],
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "example": "usuario123"
+                }
+            }
+        },
+        "model.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string",
+                    "example": "usuario123"
+                },
+                "senha": {
+                    "type": "string",
+                    "example": "senhaSegura"
+                }
+            }
+        },
+        "model.MigrationsResumo": {
+            "type": "object",
+            "properties": {
+                "pendentes": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "versao": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "versao_esperada": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "model.PapelRequest": {
+            "type": "object",
+            "required": [
+                "papel_usuario"
+            ],
+            "properties": {
+                "papel_usuario": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "model.PerfilRequest": {
+            "type": "object",
+            "properties": {
+                "login_usuario": {
+                    "type": "string",
+                    "example": "joao"
+                },
+                "nome_usuario": {
+                    "type": "string",
+                    "example": "João"
+                }
+            }
+        },
+        "model.PoolStatus": {
+            "type": "object",
+            "properties": {
+                "abertas": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "em_uso": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "esperas": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "max_abertas": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "ociosas": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "tempo_espera_ms": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "model.Prontidao": {
+            "type": "object",
+            "properties": {
+                "banco": {
+                    "$ref": "#/definitions/model.BancoResumo"
+                },
+                "migrations": {
+                    "$ref": "#/definitions/model.MigrationsResumo"
+                },
+                "rotinas": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RotinaResumo"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "model.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wE..."
+                }
+            }
+        },
+        "model.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "nova_senha",
+                "token"
+            ],
+            "properties": {
+                "nova_senha": {
+                    "type": "string",
+                    "example": "novaSenhaSegura"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "kP2x9..."
+                }
+            }
+        },
+        "model.Response": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.RotinaResumo": {
+            "type": "object",
+            "properties": {
+                "nome": {
+                    "type": "string",
+                    "example": "limpeza-tokens"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "executando"
+                }
+            }
+        },
+        "model.Sessao": {
+            "type": "object",
+            "properties": {
+                "atual": {
+                    "type": "boolean"
+                },
+                "criada_em": {
+                    "type": "string"
+                },
+                "dispositivo": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64)"
+                },
+                "id_sessao": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "192.0.2.1"
+                },
+                "ultimo_uso": {
                     "type": "string"
+                }
+            }
+        },
+        "model.StatusRequest": {
+            "type": "object",
+            "required": [
+                "status_usuario"
+            ],
+            "properties": {
+                "status_usuario": {
+                    "type": "string",
+                    "example": "suspenso"
+                }
+            }
+        },
+        "model.TarefaRequest": {
+            "type": "object",
+            "properties": {
+                "conteudo_tarefa": {
+                    "type": "string",
+                    "example": "Estudar interfaces"
+                },
+                "finalizado": {
+                    "type": "string",
+                    "example": "N"
+                },
+                "nome_tarefa": {
+                    "type": "string",
+                    "example": "Estudar Go"
+                },
+                "usuario_responsavel_tarefa": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "model.TarefaResponse": {
+            "type": "object",
+            "properties": {
+                "conteudo_tarefa": {
+                    "type": "string",
+                    "example": "Estudar interfaces"
+                },
+                "finalizado": {
+                    "type": "string",
+                    "example": "N"
                 },
                 "id_tarefa": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 1
                 },
                 "nome_tarefa": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "Estudar Go"
                 },
                 "usuario_responsavel_tarefa": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "model.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
                     "type": "string"
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.UsuarioRequest": {
+            "type": "object",
+            "required": [
+                "login_usuario",
+                "nome_usuario",
+                "senha_usuario"
+            ],
+            "properties": {
+                "login_usuario": {
+                    "type": "string",
+                    "example": "joao"
+                },
+                "nome_usuario": {
+                    "type": "string",
+                    "example": "João"
+                },
+                "papel_usuario": {
+                    "type": "string",
+                    "example": "membro"
+                },
+                "senha_usuario": {
+                    "type": "string",
+                    "example": "senha123"
+                },
+                "status_usuario": {
+                    "type": "string",
+                    "example": "ativo"
                 }
             }
         },
-        "model.Usuario": {
+        "model.UsuarioResponse": {
             "type": "object",
             "properties": {
                 "id_usuario": {
-                    "type": "integer"
+                    "type": "integer",
+                    "example": 1
                 },
                 "login_usuario": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "joao"
                 },
                 "nome_usuario": {
-                    "type": "string"
+                    "type": "string",
+                    "example": "João"
                 },
-                "senha_usuario": {
-                    "type": "string"
+                "papel_usuario": {
+                    "type": "string",
+                    "example": "membro"
+                },
+                "status_usuario": {
+                    "type": "string",
+                    "example": "ativo"
+                }
+            }
+        },
+        "model.UsuarioUpdateRequest": {
+            "type": "object",
+            "required": [
+                "login_usuario",
+                "nome_usuario"
+            ],
+            "properties": {
+                "login_usuario": {
+                    "type": "string",
+                    "example": "joao"
+                },
+                "nome_usuario": {
+                    "type": "string",
+                    "example": "João"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key criada em /api-keys",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Informe \"Bearer {token}\" com o token retornado por /auth/login ou \"Bearer {api_key}\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"go-api/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter cria o gin.Engine da API. Os cabeçalhos X-Forwarded-For e X-Real-IP só são
+// considerados em conexões vindas de cfg.TrustedProxies; sem proxies configurados, o IP do
+// cliente é sempre o endereço da conexão, para que ninguém escolha o IP contado no bloqueio
+// de login.
+func NewRouter(cfg config.ServerConfig) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+// New cria o servidor HTTP com os limites de cfg. Sem eles, um cliente lento consegue manter
+// conexões abertas indefinidamente.
+func New(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout.Duration,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout.Duration,
+		WriteTimeout:      cfg.WriteTimeout.Duration,
+		IdleTimeout:       cfg.IdleTimeout.Duration,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Run atende as requisições até ctx ser cancelado. A partir daí o servidor deixa de aceitar
+// conexões e espera as requisições em andamento terminarem por até cfg.ShutdownTimeout.
+func Run(ctx context.Context, server *http.Server, cfg config.ServerConfig) error {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, server, listener, cfg)
+}
+
+// Serve é Run sobre um listener já aberto
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, cfg config.ServerConfig) error {
+	erros := make(chan error, 1)
+	go func() {
+		fmt.Println("Servidor escutando em", listener.Addr())
+		erros <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-erros:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Println("Encerrando o servidor, aguardando as requisições em andamento")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout.Duration)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// O prazo acabou: as conexões restantes são fechadas à força
+		server.Close()
+		return fmt.Errorf("requisições interrompidas no encerramento: %w", err)
+	}
+	if err := <-erros; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"errors"
+	"go-api/config"
+	"go-api/model"
+	"go-api/usecase"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	UserIdKey = "user_id"
+	ClaimsKey = "claims"
+	CallerKey = "caller"
+)
+
+// Auth exige um token JWT ou uma API key válida em todas as rotas, exceto nas informadas em
+// publicRoutes. A API key pode vir no cabeçalho X-API-Key ou como token Bearer.
+// As rotas públicas são comparadas com o padrão registrado no gin (ex.: "/swagger/*any").
+func Auth(authUsecase *usecase.AuthUsecase, publicRoutes ...string) gin.HandlerFunc {
+	public := make(map[string]bool, len(publicRoutes))
+	for _, route := range publicRoutes {
+		public[route] = true
+	}
+
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		// Rotas não registradas seguem para o 404 padrão do gin
+		if route == "" || public[route] {
+			ctx.Next()
+			return
+		}
+
+		token, ok := bearerToken(ctx.GetHeader("Authorization"))
+		if key := ctx.GetHeader("X-API-Key"); key != "" || strings.HasPrefix(token, config.ApiKeyPrefix) {
+			if key == "" {
+				key = token
+			}
+			authenticateApiKey(ctx, authUsecase, key)
+			return
+		}
+		if !ok {
+			unauthorized(ctx, "Token de autenticação não informado")
+			return
+		}
+
+		claims, err := authUsecase.ValidateToken(token)
+		if err != nil {
+			if errors.Is(err, config.ErrTokenInvalido) {
+				unauthorized(ctx, "Token inválido ou expirado")
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao validar o token"})
+			return
+		}
+
+		ctx.Set(UserIdKey, claims.UserId)
+		ctx.Set(ClaimsKey, claims)
+		ctx.Set(CallerKey, model.Caller{UsuarioId: claims.UserId, Papel: claims.Papel})
+		ctx.Next()
+	}
+}
+
+func authenticateApiKey(ctx *gin.Context, authUsecase *usecase.AuthUsecase, key string) {
+	caller, err := authUsecase.ApiKeys.Authenticate(key)
+	if err != nil {
+		if errors.Is(err, usecase.ErrApiKeyInvalida) {
+			unauthorized(ctx, "API key inválida, revogada ou expirada")
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erro ao validar a API key"})
+		return
+	}
+
+	ctx.Set(UserIdKey, caller.UsuarioId)
+	ctx.Set(CallerKey, *caller)
+	ctx.Next()
+}
+
+// GetUserId retorna o id do usuário autenticado na requisição
+func GetUserId(ctx *gin.Context) (int, bool) {
+	userId, ok := ctx.Get(UserIdKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := userId.(int)
+	return id, ok
+}
+
+// GetCaller retorna quem fez a requisição autenticada
+func GetCaller(ctx *gin.Context) (model.Caller, bool) {
+	caller, ok := ctx.Get(CallerKey)
+	if !ok {
+		return model.Caller{}, false
+	}
+	c, ok := caller.(model.Caller)
+	return c, ok
+}
+
+// GetClaims retorna as claims do token usado na requisição
+func GetClaims(ctx *gin.Context) (*config.Claims, bool) {
+	claims, ok := ctx.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	c, ok := claims.(*config.Claims)
+	return c, ok
+}
+
+func bearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
+
+func unauthorized(ctx *gin.Context, message string) {
+	ctx.Header("WWW-Authenticate", `Bearer realm="go-api"`)
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"go-api/model"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole permite a requisição apenas para usuários com um dos papéis informados
+func RequireRole(papeis ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, ok := GetCaller(ctx)
+		if !ok {
+			unauthorized(ctx, "Token de autenticação não informado")
+			return
+		}
+
+		if !hasPapel(caller, papeis) {
+			forbidden(ctx)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireSelfOrRole permite a requisição quando o parâmetro de rota param é o id do próprio
+// usuário autenticado ou quando ele tem um dos papéis informados
+func RequireSelfOrRole(param string, papeis ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, ok := GetCaller(ctx)
+		if !ok {
+			unauthorized(ctx, "Token de autenticação não informado")
+			return
+		}
+
+		if hasPapel(caller, papeis) {
+			ctx.Next()
+			return
+		}
+
+		id, err := strconv.Atoi(ctx.Param(param))
+		if err != nil || id != caller.UsuarioId {
+			forbidden(ctx)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireScope exige que requisições feitas com API key tenham o escopo informado.
+// Requisições com JWT não têm restrição de escopo.
+func RequireScope(escopo string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, ok := GetCaller(ctx)
+		if !ok {
+			unauthorized(ctx, "Token de autenticação não informado")
+			return
+		}
+
+		if !caller.HasEscopo(escopo) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "A API key não possui o escopo " + escopo})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireSession recusa requisições feitas com API key, para operações que exigem o login do
+// próprio usuário, como gerenciar API keys e o segundo fator
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, ok := GetCaller(ctx)
+		if !ok {
+			unauthorized(ctx, "Token de autenticação não informado")
+			return
+		}
+
+		if caller.IsApiKey() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Esta operação não pode ser feita com API key"})
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func hasPapel(caller model.Caller, papeis []string) bool {
+	for _, papel := range papeis {
+		if caller.Papel == papel {
+			return true
+		}
+	}
+	return false
+}
+
+func forbidden(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+}
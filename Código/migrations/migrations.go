@@ -0,0 +1,455 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-api/config"
+)
+
+// As migrations de cada backend ficam em um diretório com o nome do dialeto, em pares
+// NNNN_nome.up.sql e NNNN_nome.down.sql. As versões são as mesmas em todos os dialetos.
+//
+//go:embed mysql postgres sqlite
+var arquivos embed.FS
+
+var (
+	ErrEsquemaMaisNovo    = errors.New("o esquema do banco é mais novo que esta versão da API")
+	ErrMigrationBloqueada = errors.New("outra instância está aplicando as migrations")
+)
+
+// LockTimeout limita a espera pelo lock enquanto outra instância aplica as migrations
+var LockTimeout = time.Minute
+
+// Migration é um passo versionado do esquema
+type Migration struct {
+	Versao int
+	Nome   string
+	up     string
+	down   string
+}
+
+// Status é uma migration com a data em que foi aplicada, nil se ainda estiver pendente.
+// Versões aplicadas que esta versão da API não conhece aparecem sem nome.
+type Status struct {
+	Versao     int
+	Nome       string
+	AplicadaEm *time.Time
+}
+
+// Migrator aplica e reverte as migrations do backend em um banco, registrando as versões
+// aplicadas na tabela schema_migrations. Todas as alterações são feitas sob um lock no
+// próprio banco, de modo que duas instâncias iniciando juntas não migram ao mesmo tempo.
+type Migrator struct {
+	db         *sql.DB
+	dialeto    dialeto
+	migrations []Migration
+}
+
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	d, ok := dialetos[driver]
+	if !ok {
+		return nil, fmt.Errorf("driver de banco de dados %q não suportado", driver)
+	}
+	migrations, err := Load(driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialeto: d, migrations: migrations}, nil
+}
+
+// Load lê as migrations embutidas do driver, em ordem de versão
+func Load(driver string) ([]Migration, error) {
+	d, ok := dialetos[driver]
+	if !ok {
+		return nil, fmt.Errorf("driver de banco de dados %q não suportado", driver)
+	}
+	entries, err := fs.ReadDir(arquivos, d.diretorio)
+	if err != nil {
+		return nil, err
+	}
+
+	porVersao := map[int]*Migration{}
+	for _, entry := range entries {
+		nome, direcao, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), ".")
+		prefixo, descricao, ok2 := strings.Cut(nome, "_")
+		versao, err := strconv.Atoi(prefixo)
+		if !ok || !ok2 || err != nil || versao <= 0 || (direcao != "up" && direcao != "down") {
+			return nil, fmt.Errorf("migration %s/%s: use o formato NNNN_nome.up.sql ou NNNN_nome.down.sql", d.diretorio, entry.Name())
+		}
+
+		conteudo, err := fs.ReadFile(arquivos, path.Join(d.diretorio, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := porVersao[versao]
+		if !ok {
+			m = &Migration{Versao: versao, Nome: descricao}
+			porVersao[versao] = m
+		}
+		if m.Nome != descricao {
+			return nil, fmt.Errorf("migration %s/%04d: nomes diferentes em %s e %s", d.diretorio, versao, m.Nome, descricao)
+		}
+		if direcao == "up" {
+			m.up = string(conteudo)
+		} else {
+			m.down = string(conteudo)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(porVersao))
+	for _, m := range porVersao {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %s/%04d_%s: faltam o up ou o down", d.diretorio, m.Versao, m.Nome)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Versao < migrations[j].Versao })
+	return migrations, nil
+}
+
+// Up aplica as migrations pendentes em ordem e retorna as que foram aplicadas. Se outra
+// instância estiver migrando, espera ela terminar por até LockTimeout.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var aplicadasAgora []Migration
+	err := m.sobLock(ctx, func(conn *sql.Conn, aplicadas map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := aplicadas[migration.Versao]; ok {
+				continue
+			}
+			err := m.executa(ctx, conn, migration.up, m.dialeto.registra, migration.Versao, migration.Nome, time.Now())
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Versao, migration.Nome, err)
+			}
+			aplicadasAgora = append(aplicadasAgora, migration)
+		}
+		return nil
+	})
+	return aplicadasAgora, err
+}
+
+// Down reverte as últimas passos migrations aplicadas, da mais nova para a mais antiga, e
+// retorna as que foram revertidas
+func (m *Migrator) Down(ctx context.Context, passos int) ([]Migration, error) {
+	var revertidas []Migration
+	err := m.sobLock(ctx, func(conn *sql.Conn, aplicadas map[int]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(revertidas) < passos; i-- {
+			migration := m.migrations[i]
+			if _, ok := aplicadas[migration.Versao]; !ok {
+				continue
+			}
+			err := m.executa(ctx, conn, migration.down, m.dialeto.remove, migration.Versao)
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Versao, migration.Nome, err)
+			}
+			revertidas = append(revertidas, migration)
+		}
+		return nil
+	})
+	return revertidas, err
+}
+
+// Status lista as migrations conhecidas e as versões aplicadas no banco. Como Pending, apenas
+// lê o banco: /readyz a chama a cada verificação, inclusive com um usuário só de leitura.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	aplicadas, err := m.consultaAplicadas(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var status []Status
+	for _, migration := range m.migrations {
+		s := Status{Versao: migration.Versao, Nome: migration.Nome}
+		if aplicadaEm, ok := aplicadas[migration.Versao]; ok {
+			s.AplicadaEm = &aplicadaEm
+			delete(aplicadas, migration.Versao)
+		}
+		status = append(status, s)
+	}
+
+	var desconhecidas []Status
+	for versao, aplicadaEm := range aplicadas {
+		aplicadaEm := aplicadaEm
+		desconhecidas = append(desconhecidas, Status{Versao: versao, AplicadaEm: &aplicadaEm})
+	}
+	sort.Slice(desconhecidas, func(i, j int) bool { return desconhecidas[i].Versao < desconhecidas[j].Versao })
+	return append(status, desconhecidas...), nil
+}
+
+// Pending retorna as migrations que ainda não foram aplicadas, ou ErrEsquemaMaisNovo se o
+// banco já tiver passado por migrations que esta versão da API não conhece. Nesse caso a
+// API não deve iniciar: o esquema pode ter mudado de um jeito que ela não sabe usar.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	aplicadas, err := m.consultaAplicadas(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verificaVersao(aplicadas); err != nil {
+		return nil, err
+	}
+
+	var pendentes []Migration
+	for _, migration := range m.migrations {
+		if _, ok := aplicadas[migration.Versao]; !ok {
+			pendentes = append(pendentes, migration)
+		}
+	}
+	return pendentes, nil
+}
+
+// Versao retorna a versão mais recente que esta versão da API conhece
+func (m *Migrator) Versao() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Versao
+}
+
+func (m *Migrator) verificaVersao(aplicadas map[int]time.Time) error {
+	for versao := range aplicadas {
+		if versao > m.Versao() {
+			return fmt.Errorf("%w: o banco tem a migration %04d e a API conhece até a %04d", ErrEsquemaMaisNovo, versao, m.Versao())
+		}
+	}
+	return nil
+}
+
+// sobLock executa fn em uma conexão exclusiva, com o lock de migrations adquirido e as
+// versões já aplicadas carregadas
+func (m *Migrator) sobLock(ctx context.Context, fn func(conn *sql.Conn, aplicadas map[int]time.Time) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockCtx, cancel := context.WithTimeout(ctx, LockTimeout)
+	err = m.dialeto.lock(lockCtx, conn)
+	expirou := lockCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	if err != nil {
+		if expirou {
+			return ErrMigrationBloqueada
+		}
+		return err
+	}
+
+	aplicadas, err := m.aplicadas(ctx, conn)
+	if err == nil {
+		err = m.verificaVersao(aplicadas)
+	}
+	if err == nil {
+		err = fn(conn, aplicadas)
+	}
+
+	// O lock precisa ser liberado mesmo com ctx cancelado, senão fica preso à conexão devolvida ao pool
+	if unlockErr := m.dialeto.unlock(context.WithoutCancel(ctx), conn, err); unlockErr != nil && err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// aplicadas cria schema_migrations se preciso e retorna as versões aplicadas
+func (m *Migrator) aplicadas(ctx context.Context, conn execer) (map[int]time.Time, error) {
+	if _, err := conn.ExecContext(ctx, m.dialeto.criaTabela); err != nil {
+		return nil, fmt.Errorf("schema_migrations: %w", err)
+	}
+	return m.leAplicadas(ctx, conn)
+}
+
+// consultaAplicadas retorna as versões aplicadas sem criar schema_migrations nem tomar o lock.
+// Um banco sem a tabela ainda não recebeu nenhuma migration.
+func (m *Migrator) consultaAplicadas(ctx context.Context, conn execer) (map[int]time.Time, error) {
+	var tabelas int
+	if err := conn.QueryRowContext(ctx, m.dialeto.existeTabela).Scan(&tabelas); err != nil {
+		return nil, fmt.Errorf("schema_migrations: %w", err)
+	}
+	if tabelas == 0 {
+		return map[int]time.Time{}, nil
+	}
+	return m.leAplicadas(ctx, conn)
+}
+
+func (m *Migrator) leAplicadas(ctx context.Context, conn execer) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT versao, aplicada_em FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	aplicadas := map[int]time.Time{}
+	for rows.Next() {
+		var versao int
+		var aplicadaEm time.Time
+		if err := rows.Scan(&versao, &aplicadaEm); err != nil {
+			return nil, err
+		}
+		aplicadas[versao] = aplicadaEm
+	}
+	return aplicadas, rows.Err()
+}
+
+// executa roda os comandos do script e a atualização de schema_migrations. Nos dialetos com
+// DDL transacional, tudo acontece em uma transação e uma falha não deixa o esquema pela metade.
+func (m *Migrator) executa(ctx context.Context, conn *sql.Conn, script, registro string, args ...any) error {
+	var exec execer = conn
+	var tx *sql.Tx
+	if m.dialeto.transacional {
+		var err error
+		tx, err = conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		exec = tx
+	}
+
+	for _, comando := range comandos(script) {
+		if _, err := exec.ExecContext(ctx, comando); err != nil {
+			return err
+		}
+	}
+	if _, err := exec.ExecContext(ctx, registro, args...); err != nil {
+		return err
+	}
+
+	if tx != nil {
+		return tx.Commit()
+	}
+	return nil
+}
+
+// comandos separa o script nos ";" que terminam cada comando, já que o driver do MySQL só
+// executa um comando por vez. Linhas de comentário são descartadas.
+func comandos(script string) []string {
+	var linhas []string
+	for _, linha := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(linha), "--") {
+			linhas = append(linhas, linha)
+		}
+	}
+
+	var resultado []string
+	for _, comando := range strings.Split(strings.Join(linhas, "\n"), ";") {
+		if comando = strings.TrimSpace(comando); comando != "" {
+			resultado = append(resultado, comando)
+		}
+	}
+	return resultado
+}
+
+// dialeto reúne o que muda de um backend para outro
+type dialeto struct {
+	diretorio  string
+	criaTabela string
+	// existeTabela conta as tabelas schema_migrations visíveis, sem alterar o banco
+	existeTabela string
+	registra     string
+	remove       string
+	// transacional indica que o DDL pode ser desfeito por rollback
+	transacional bool
+	lock         func(ctx context.Context, conn *sql.Conn) error
+	// unlock recebe o erro da migração, para os dialetos em que o lock é uma transação
+	unlock func(ctx context.Context, conn *sql.Conn, err error) error
+}
+
+// Chaves dos locks de migração no MySQL e no PostgreSQL
+const (
+	mysqlLock    = "go-api:schema_migrations"
+	postgresLock = 7365726369 // arbitrária, apenas precisa ser a mesma em todas as instâncias
+)
+
+var dialetos = map[string]dialeto{
+	config.DriverMySQL: {
+		diretorio: "mysql",
+		criaTabela: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			versao INT PRIMARY KEY,
+			nome VARCHAR(255) NOT NULL,
+			aplicada_em DATETIME NOT NULL
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`,
+		existeTabela: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'",
+		registra:     "INSERT INTO schema_migrations (versao, nome, aplicada_em) VALUES (?, ?, ?)",
+		remove:       "DELETE FROM schema_migrations WHERE versao = ?",
+		// O MySQL confirma o DDL implicitamente; uma migration com falha precisa ser corrigida à mão
+		transacional: false,
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			var obtido sql.NullInt64
+			segundos := int(LockTimeout / time.Second)
+			if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", mysqlLock, segundos).Scan(&obtido); err != nil {
+				return err
+			}
+			if obtido.Int64 != 1 {
+				return ErrMigrationBloqueada
+			}
+			return nil
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn, _ error) error {
+			var liberado sql.NullInt64
+			return conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", mysqlLock).Scan(&liberado)
+		},
+	},
+	config.DriverPostgres: {
+		diretorio: "postgres",
+		criaTabela: `CREATE TABLE IF NOT EXISTS schema_migrations (
+			versao INTEGER PRIMARY KEY,
+			nome TEXT NOT NULL,
+			aplicada_em TIMESTAMPTZ NOT NULL
+		)`,
+		existeTabela: "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'",
+		registra:     "INSERT INTO schema_migrations (versao, nome, aplicada_em) VALUES ($1, $2, $3)",
+		remove:       "DELETE FROM schema_migrations WHERE versao = $1",
+		transacional: true,
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			var obtido string
+			return conn.QueryRowContext(ctx, "SELECT pg_advisory_lock($1)::text", postgresLock).Scan(&obtido)
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn, _ error) error {
+			var liberado bool
+			return conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLock).Scan(&liberado)
+		},
+	},
+	config.DriverSQLite: sqlite,
+	config.DriverMemory: sqlite,
+}
+
+// No SQLite o lock é uma transação de escrita sobre a migração inteira: ela bloqueia as
+// demais conexões ao arquivo e, como o DDL é transacional, uma falha desfaz tudo.
+var sqlite = dialeto{
+	diretorio: "sqlite",
+	criaTabela: `CREATE TABLE IF NOT EXISTS schema_migrations (
+		versao INTEGER PRIMARY KEY,
+		nome TEXT NOT NULL,
+		aplicada_em DATETIME NOT NULL
+	)`,
+	existeTabela: "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	registra:     "INSERT INTO schema_migrations (versao, nome, aplicada_em) VALUES (?, ?, ?)",
+	remove:       "DELETE FROM schema_migrations WHERE versao = ?",
+	lock: func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+		return err
+	},
+	unlock: func(ctx context.Context, conn *sql.Conn, err error) error {
+		if err != nil {
+			_, rollbackErr := conn.ExecContext(ctx, "ROLLBACK")
+			return rollbackErr
+		}
+		_, err = conn.ExecContext(ctx, "COMMIT")
+		return err
+	},
+}
@@ -0,0 +1,23 @@
+-- As migrations partem de um esquema vazio. Tabelas criadas à mão, antes das migrations
+-- existirem, não têm colunas que a API usa, como papel; nesses bancos a criação falha em vez
+-- de adotá-los pela metade, e os dados precisam ser copiados para um esquema novo.
+
+CREATE TABLE usuario (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    login VARCHAR(255) NOT NULL UNIQUE,
+    senha VARCHAR(255) NOT NULL,
+    papel VARCHAR(20) NOT NULL DEFAULT 'membro',
+    ativo CHAR(1) NOT NULL DEFAULT 'A'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE tarefa (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    nome VARCHAR(255) NOT NULL,
+    conteudo TEXT NOT NULL,
+    usuario_responsavel INT NOT NULL,
+    finalizado CHAR(1) NOT NULL DEFAULT 'N',
+    ativo CHAR(1) NOT NULL DEFAULT 'A',
+    INDEX idx_tarefa_usuario (usuario_responsavel),
+    FOREIGN KEY (usuario_responsavel) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,84 @@
+CREATE TABLE IF NOT EXISTS token_revogado (
+    jti VARCHAR(64) PRIMARY KEY,
+    expira_em DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    usuario_id INT NOT NULL,
+    familia VARCHAR(64) NOT NULL,
+    expira_em DATETIME NOT NULL,
+    usado_em DATETIME NULL,
+    revogado CHAR(1) NOT NULL DEFAULT 'N',
+    INDEX idx_refresh_token_familia (familia),
+    INDEX idx_refresh_token_usuario (usuario_id),
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sessao (
+    id VARCHAR(64) PRIMARY KEY,
+    usuario_id INT NOT NULL,
+    dispositivo VARCHAR(255) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    criada_em DATETIME NOT NULL,
+    ultimo_uso DATETIME NOT NULL,
+    encerrada_em DATETIME NULL,
+    INDEX idx_sessao_usuario (usuario_id),
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS tentativa_login (
+    chave VARCHAR(300) PRIMARY KEY,
+    falhas INT NOT NULL,
+    bloqueado_ate DATETIME NOT NULL,
+    atualizado_em DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS reset_senha (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    usuario_id INT NOT NULL,
+    expira_em DATETIME NOT NULL,
+    usado_em DATETIME NULL,
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS usuario_2fa (
+    usuario_id INT PRIMARY KEY,
+    segredo VARCHAR(64) NOT NULL,
+    confirmado CHAR(1) NOT NULL DEFAULT 'N',
+    ultimo_passo BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS codigo_recuperacao (
+    usuario_id INT NOT NULL,
+    codigo_hash CHAR(64) NOT NULL,
+    usado_em DATETIME NULL,
+    PRIMARY KEY (usuario_id, codigo_hash),
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS api_key (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    usuario_id INT NOT NULL,
+    nome VARCHAR(255) NOT NULL,
+    prefixo VARCHAR(32) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    escopos VARCHAR(255) NOT NULL,
+    expira_em DATETIME NULL,
+    ultimo_uso DATETIME NULL,
+    revogada CHAR(1) NOT NULL DEFAULT 'N',
+    criada_em DATETIME NOT NULL,
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS usuario_identidade (
+    usuario_id INT NOT NULL,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    criada_em DATETIME NOT NULL,
+    PRIMARY KEY (issuer, subject),
+    FOREIGN KEY (usuario_id) REFERENCES usuario (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS tarefa;
+DROP TABLE IF EXISTS usuario;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS usuario_identidade;
+DROP TABLE IF EXISTS api_key;
+DROP TABLE IF EXISTS codigo_recuperacao;
+DROP TABLE IF EXISTS usuario_2fa;
+DROP TABLE IF EXISTS reset_senha;
+DROP TABLE IF EXISTS tentativa_login;
+DROP TABLE IF EXISTS sessao;
+DROP TABLE IF EXISTS refresh_token;
+DROP TABLE IF EXISTS token_revogado;
@@ -0,0 +1,79 @@
+CREATE TABLE IF NOT EXISTS token_revogado (
+    jti TEXT PRIMARY KEY,
+    expira_em TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id SERIAL PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    familia TEXT NOT NULL,
+    expira_em TIMESTAMPTZ NOT NULL,
+    usado_em TIMESTAMPTZ,
+    revogado CHAR(1) NOT NULL DEFAULT 'N'
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_familia ON refresh_token (familia);
+CREATE INDEX IF NOT EXISTS idx_refresh_token_usuario ON refresh_token (usuario_id);
+
+CREATE TABLE IF NOT EXISTS sessao (
+    id TEXT PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    dispositivo TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    criada_em TIMESTAMPTZ NOT NULL,
+    ultimo_uso TIMESTAMPTZ NOT NULL,
+    encerrada_em TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessao_usuario ON sessao (usuario_id);
+
+CREATE TABLE IF NOT EXISTS tentativa_login (
+    chave TEXT PRIMARY KEY,
+    falhas INTEGER NOT NULL,
+    bloqueado_ate TIMESTAMPTZ NOT NULL,
+    atualizado_em TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reset_senha (
+    id SERIAL PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    expira_em TIMESTAMPTZ NOT NULL,
+    usado_em TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS usuario_2fa (
+    usuario_id INTEGER PRIMARY KEY REFERENCES usuario (id),
+    segredo TEXT NOT NULL,
+    confirmado CHAR(1) NOT NULL DEFAULT 'N',
+    ultimo_passo BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS codigo_recuperacao (
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    codigo_hash TEXT NOT NULL,
+    usado_em TIMESTAMPTZ,
+    PRIMARY KEY (usuario_id, codigo_hash)
+);
+
+CREATE TABLE IF NOT EXISTS api_key (
+    id SERIAL PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    nome TEXT NOT NULL,
+    prefixo TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    escopos TEXT NOT NULL,
+    expira_em TIMESTAMPTZ,
+    ultimo_uso TIMESTAMPTZ,
+    revogada CHAR(1) NOT NULL DEFAULT 'N',
+    criada_em TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS usuario_identidade (
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    criada_em TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (issuer, subject)
+);
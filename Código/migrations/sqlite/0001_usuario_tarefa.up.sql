@@ -0,0 +1,23 @@
+-- As migrations partem de um esquema vazio. Tabelas criadas à mão, antes das migrations
+-- existirem, não têm colunas que a API usa, como papel; nesses bancos a criação falha em vez
+-- de adotá-los pela metade, e os dados precisam ser copiados para um esquema novo.
+
+CREATE TABLE usuario (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    nome TEXT NOT NULL,
+    login TEXT NOT NULL UNIQUE,
+    senha TEXT NOT NULL,
+    papel TEXT NOT NULL DEFAULT 'membro',
+    ativo TEXT NOT NULL DEFAULT 'A'
+);
+
+CREATE TABLE tarefa (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    nome TEXT NOT NULL,
+    conteudo TEXT NOT NULL,
+    usuario_responsavel INTEGER NOT NULL REFERENCES usuario (id),
+    finalizado TEXT NOT NULL DEFAULT 'N',
+    ativo TEXT NOT NULL DEFAULT 'A'
+);
+
+CREATE INDEX idx_tarefa_usuario ON tarefa (usuario_responsavel);
@@ -0,0 +1,79 @@
+CREATE TABLE IF NOT EXISTS token_revogado (
+    jti TEXT PRIMARY KEY,
+    expira_em DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    familia TEXT NOT NULL,
+    expira_em DATETIME NOT NULL,
+    usado_em DATETIME,
+    revogado TEXT NOT NULL DEFAULT 'N'
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_familia ON refresh_token (familia);
+CREATE INDEX IF NOT EXISTS idx_refresh_token_usuario ON refresh_token (usuario_id);
+
+CREATE TABLE IF NOT EXISTS sessao (
+    id TEXT PRIMARY KEY,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    dispositivo TEXT NOT NULL,
+    ip TEXT NOT NULL,
+    criada_em DATETIME NOT NULL,
+    ultimo_uso DATETIME NOT NULL,
+    encerrada_em DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessao_usuario ON sessao (usuario_id);
+
+CREATE TABLE IF NOT EXISTS tentativa_login (
+    chave TEXT PRIMARY KEY,
+    falhas INTEGER NOT NULL,
+    bloqueado_ate DATETIME NOT NULL,
+    atualizado_em DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reset_senha (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    token_hash TEXT NOT NULL UNIQUE,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    expira_em DATETIME NOT NULL,
+    usado_em DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS usuario_2fa (
+    usuario_id INTEGER PRIMARY KEY REFERENCES usuario (id),
+    segredo TEXT NOT NULL,
+    confirmado TEXT NOT NULL DEFAULT 'N',
+    ultimo_passo INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS codigo_recuperacao (
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    codigo_hash TEXT NOT NULL,
+    usado_em DATETIME,
+    PRIMARY KEY (usuario_id, codigo_hash)
+);
+
+CREATE TABLE IF NOT EXISTS api_key (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    nome TEXT NOT NULL,
+    prefixo TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    escopos TEXT NOT NULL,
+    expira_em DATETIME,
+    ultimo_uso DATETIME,
+    revogada TEXT NOT NULL DEFAULT 'N',
+    criada_em DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS usuario_identidade (
+    usuario_id INTEGER NOT NULL REFERENCES usuario (id),
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    criada_em DATETIME NOT NULL,
+    PRIMARY KEY (issuer, subject)
+);
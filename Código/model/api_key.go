@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Escopos que podem ser concedidos a uma API key
+const (
+	EscopoTarefasLeitura  = "tarefas:read"
+	EscopoTarefasEscrita  = "tarefas:write"
+	EscopoUsuariosLeitura = "usuarios:read"
+	EscopoUsuariosEscrita = "usuarios:write"
+)
+
+var escoposValidos = map[string]bool{
+	EscopoTarefasLeitura:  true,
+	EscopoTarefasEscrita:  true,
+	EscopoUsuariosLeitura: true,
+	EscopoUsuariosEscrita: true,
+}
+
+func IsEscopoValido(escopo string) bool {
+	return escoposValidos[escopo]
+}
+
+// ApiKey é uma chave de acesso para clientes automatizados. Apenas o hash da chave é gravado;
+// Prefixo guarda o início dela para que o usuário consiga identificá-la na listagem.
+type ApiKey struct {
+	Id        int        `json:"id_api_key"`
+	UsuarioId int        `json:"usuario_id"`
+	Nome      string     `json:"nome" example:"deploy-ci"`
+	Prefixo   string     `json:"prefixo" example:"gak_3q2x7wEa"`
+	KeyHash   string     `json:"-"`
+	Escopos   []string   `json:"escopos" example:"tarefas:read,tarefas:write"`
+	ExpiraEm  *time.Time `json:"expira_em,omitempty"`
+	UltimoUso *time.Time `json:"ultimo_uso,omitempty"`
+	Revogada  string     `json:"-"`
+	CriadaEm  time.Time  `json:"criada_em"`
+}
+
+type ApiKeyRequest struct {
+	Nome     string     `json:"nome" binding:"required" example:"deploy-ci"`
+	Escopos  []string   `json:"escopos" binding:"required" example:"tarefas:read,tarefas:write"`
+	ExpiraEm *time.Time `json:"expira_em" example:"2026-12-31T23:59:59Z"`
+}
+
+// ApiKeyCreatedResponse é retornada apenas na criação, a única vez em que a chave é exibida
+type ApiKeyCreatedResponse struct {
+	ApiKey
+	Key string `json:"api_key" example:"gak_3q2x7wEa..."`
+}
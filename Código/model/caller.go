@@ -0,0 +1,31 @@
+package model
+
+// Caller identifica quem fez a requisição autenticada. Requisições feitas com API key trazem
+// o id da chave e os escopos concedidos a ela; com JWT, ApiKeyId é zero e não há restrição de escopo.
+type Caller struct {
+	UsuarioId int
+	Papel     string
+	ApiKeyId  int
+	Escopos   []string
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.Papel == PapelAdmin
+}
+
+func (c Caller) IsApiKey() bool {
+	return c.ApiKeyId != 0
+}
+
+// HasEscopo indica se a requisição pode usar o escopo informado
+func (c Caller) HasEscopo(escopo string) bool {
+	if !c.IsApiKey() {
+		return true
+	}
+	for _, e := range c.Escopos {
+		if e == escopo {
+			return true
+		}
+	}
+	return false
+}
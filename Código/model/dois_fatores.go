@@ -0,0 +1,29 @@
+package model
+
+// DoisFatores guarda o segredo TOTP do usuário. UltimoPasso é o passo do último código aceito,
+// usado para impedir que o mesmo código seja usado duas vezes.
+type DoisFatores struct {
+	UsuarioId   int
+	Segredo     string
+	Confirmado  string
+	UltimoPasso int64
+}
+
+type DoisFatoresEnrollResponse struct {
+	Segredo    string `json:"segredo" example:"JBSWY3DPEHPK3PXP"`
+	OtpauthURI string `json:"otpauth_uri" example:"otpauth://totp/go-api:usuario123?secret=JBSWY3DPEHPK3PXP&issuer=go-api"`
+}
+
+type DoisFatoresCodigoRequest struct {
+	Codigo string `json:"codigo" binding:"required" example:"123456"`
+}
+
+type DoisFatoresConfirmResponse struct {
+	CodigosRecuperacao []string `json:"codigos_recuperacao"`
+}
+
+// DoisFatoresVerifyRequest aceita o código do autenticador ou um código de recuperação
+type DoisFatoresVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Codigo         string `json:"codigo" binding:"required" example:"123456"`
+}
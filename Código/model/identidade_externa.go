@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// IdentidadeExterna liga um usuário local ao sujeito (sub) de um provedor OpenID Connect
+type IdentidadeExterna struct {
+	UsuarioId int
+	Issuer    string
+	Subject   string
+	CriadaEm  time.Time
+}
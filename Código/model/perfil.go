@@ -0,0 +1,12 @@
+package model
+
+// PerfilRequest altera os dados do próprio usuário. Campos omitidos são mantidos.
+type PerfilRequest struct {
+	Nome  *string `json:"nome_usuario" example:"João"`
+	Login *string `json:"login_usuario" example:"joao"`
+}
+
+type ChangePasswordRequest struct {
+	SenhaAtual string `json:"senha_atual" binding:"required"`
+	NovaSenha  string `json:"nova_senha" binding:"required"`
+}
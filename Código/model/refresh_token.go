@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type RefreshToken struct {
+	Id        int
+	TokenHash string
+	UsuarioId int
+	Familia   string
+	ExpiraEm  time.Time
+	UsadoEm   *time.Time
+	Revogado  string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"3q2-7wE..."`
+}
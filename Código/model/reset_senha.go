@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type ResetSenha struct {
+	Id        int
+	TokenHash string
+	UsuarioId int
+	ExpiraEm  time.Time
+	UsadoEm   *time.Time
+}
+
+type ForgotPasswordRequest struct {
+	Login string `json:"login" binding:"required" example:"usuario123"`
+}
+
+type ResetPasswordRequest struct {
+	Token     string `json:"token" binding:"required" example:"kP2x9..."`
+	NovaSenha string `json:"nova_senha" binding:"required" example:"novaSenhaSegura"`
+}
@@ -0,0 +1,110 @@
+package model
+
+import "time"
+
+// Situações dos componentes verificados por /readyz
+const (
+	SaudeOk           = "ok"
+	SaudeIndisponivel = "indisponivel"
+)
+
+// Situações de uma rotina em segundo plano
+const (
+	RotinaExecutando = "executando"
+	RotinaEncerrada  = "encerrada"
+	RotinaFalhou     = "falhou"
+)
+
+// Prontidao é a resposta de /readyz. Como a rota é pública, ela traz a situação e os números de
+// cada componente, mas não os erros do driver nem os nomes das migrations; esses ficam no
+// Diagnostico, registrado no log quando algo não está ok.
+type Prontidao struct {
+	Status     string           `json:"status" example:"ok"`
+	Banco      BancoResumo      `json:"banco"`
+	Migrations MigrationsResumo `json:"migrations"`
+	Rotinas    []RotinaResumo   `json:"rotinas"`
+}
+
+type BancoResumo struct {
+	Status     string     `json:"status" example:"ok"`
+	LatenciaMs int64      `json:"latencia_ms" example:"2"`
+	Pool       PoolStatus `json:"pool"`
+}
+
+type MigrationsResumo struct {
+	Status         string `json:"status" example:"ok"`
+	Versao         int    `json:"versao" example:"2"`
+	VersaoEsperada int    `json:"versao_esperada" example:"2"`
+	Pendentes      int    `json:"pendentes" example:"0"`
+}
+
+type RotinaResumo struct {
+	Nome   string `json:"nome" example:"limpeza-tokens"`
+	Status string `json:"status" example:"executando"`
+}
+
+// Diagnostico é a verificação completa da instância: erros do driver, estatísticas do pool de
+// conexões e migrations pendentes. Status só é ok quando todos os componentes estão ok.
+type Diagnostico struct {
+	Status     string           `json:"status"`
+	Banco      BancoStatus      `json:"banco"`
+	Migrations MigrationsStatus `json:"migrations"`
+	Rotinas    []RotinaStatus   `json:"rotinas"`
+}
+
+// Prontidao resume o diagnóstico para a resposta pública de /readyz
+func (d Diagnostico) Prontidao() Prontidao {
+	prontidao := Prontidao{
+		Status: d.Status,
+		Banco: BancoResumo{
+			Status:     d.Banco.Status,
+			LatenciaMs: d.Banco.LatenciaMs,
+			Pool:       d.Banco.Pool,
+		},
+		Migrations: MigrationsResumo{
+			Status:         d.Migrations.Status,
+			Versao:         d.Migrations.Versao,
+			VersaoEsperada: d.Migrations.VersaoEsperada,
+			Pendentes:      len(d.Migrations.Pendentes),
+		},
+		Rotinas: make([]RotinaResumo, len(d.Rotinas)),
+	}
+	for i, rotina := range d.Rotinas {
+		prontidao.Rotinas[i] = RotinaResumo{Nome: rotina.Nome, Status: rotina.Status}
+	}
+	return prontidao
+}
+
+// BancoStatus é o resultado do ping no banco e as estatísticas do pool de conexões
+type BancoStatus struct {
+	Status     string     `json:"status" example:"ok"`
+	Erro       string     `json:"erro,omitempty"`
+	LatenciaMs int64      `json:"latencia_ms" example:"2"`
+	Pool       PoolStatus `json:"pool"`
+}
+
+type PoolStatus struct {
+	MaxAbertas    int   `json:"max_abertas" example:"25"`
+	Abertas       int   `json:"abertas" example:"3"`
+	EmUso         int   `json:"em_uso" example:"1"`
+	Ociosas       int   `json:"ociosas" example:"2"`
+	Esperas       int64 `json:"esperas" example:"0"`
+	TempoEsperaMs int64 `json:"tempo_espera_ms" example:"0"`
+}
+
+// MigrationsStatus compara a versão do esquema do banco com a que esta versão da API espera
+type MigrationsStatus struct {
+	Status         string   `json:"status" example:"ok"`
+	Erro           string   `json:"erro,omitempty"`
+	Versao         int      `json:"versao" example:"2"`
+	VersaoEsperada int      `json:"versao_esperada" example:"2"`
+	Pendentes      []string `json:"pendentes,omitempty" example:"0002_autenticacao"`
+}
+
+// RotinaStatus é a situação de uma rotina em segundo plano, como a limpeza de tokens expirados
+type RotinaStatus struct {
+	Nome       string    `json:"nome" example:"limpeza-tokens"`
+	Status     string    `json:"status" example:"executando"`
+	Erro       string    `json:"erro,omitempty"`
+	IniciadaEm time.Time `json:"iniciada_em"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Origem identifica de onde partiu uma requisição de login
+type Origem struct {
+	IP        string
+	UserAgent string
+}
+
+// Sessao é um login ativo do usuário. O id é o mesmo da família de refresh tokens e viaja
+// na claim sid do token de acesso.
+type Sessao struct {
+	Id          string     `json:"id_sessao"`
+	UsuarioId   int        `json:"-"`
+	Dispositivo string     `json:"dispositivo" example:"Mozilla/5.0 (X11; Linux x86_64)"`
+	IP          string     `json:"ip" example:"192.0.2.1"`
+	CriadaEm    time.Time  `json:"criada_em"`
+	UltimoUso   time.Time  `json:"ultimo_uso"`
+	EncerradaEm *time.Time `json:"-"`
+	Atual       bool       `json:"atual"`
+}
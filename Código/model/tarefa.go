@@ -1,5 +1,6 @@
 package model
 
+// Tarefa é o registro gravado no banco. As respostas usam TarefaResponse.
 type Tarefa struct {
 	Id          int    `json:"id_tarefa"`
 	Nome        string `json:"nome_tarefa"`
@@ -7,3 +8,42 @@ type Tarefa struct {
 	UsuarioResp string `json:"usuario_responsavel_tarefa"`
 	Finalizado  string `json:"finalizado"`
 }
+
+// TarefaRequest é o corpo aceito na criação e na atualização de tarefas. O id vem apenas da rota.
+type TarefaRequest struct {
+	Nome        string `json:"nome_tarefa" example:"Estudar Go"`
+	Conteudo    string `json:"conteudo_tarefa" example:"Estudar interfaces"`
+	UsuarioResp string `json:"usuario_responsavel_tarefa" example:"1"`
+	Finalizado  string `json:"finalizado" example:"N"`
+}
+
+func (r TarefaRequest) ToTarefa() Tarefa {
+	return Tarefa{Nome: r.Nome, Conteudo: r.Conteudo, UsuarioResp: r.UsuarioResp, Finalizado: r.Finalizado}
+}
+
+// TarefaResponse é a representação pública da tarefa
+type TarefaResponse struct {
+	Id          int    `json:"id_tarefa" example:"1"`
+	Nome        string `json:"nome_tarefa" example:"Estudar Go"`
+	Conteudo    string `json:"conteudo_tarefa" example:"Estudar interfaces"`
+	UsuarioResp string `json:"usuario_responsavel_tarefa" example:"1"`
+	Finalizado  string `json:"finalizado" example:"N"`
+}
+
+func NewTarefaResponse(tarefa Tarefa) TarefaResponse {
+	return TarefaResponse{
+		Id:          tarefa.Id,
+		Nome:        tarefa.Nome,
+		Conteudo:    tarefa.Conteudo,
+		UsuarioResp: tarefa.UsuarioResp,
+		Finalizado:  tarefa.Finalizado,
+	}
+}
+
+func NewTarefaResponses(tarefas []Tarefa) []TarefaResponse {
+	response := make([]TarefaResponse, 0, len(tarefas))
+	for _, tarefa := range tarefas {
+		response = append(response, NewTarefaResponse(tarefa))
+	}
+	return response
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// TentativaLogin acumula as falhas de login de uma chave, que identifica um login ou um IP
+type TentativaLogin struct {
+	Chave        string
+	Falhas       int
+	BloqueadoAte time.Time
+	AtualizadoEm time.Time
+}
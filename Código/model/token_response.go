@@ -0,0 +1,12 @@
+package model
+
+// TokenResponse é a resposta do login e da renovação. Quando o usuário tem segundo fator,
+// o login retorna apenas DoisFatores e ChallengeToken, a ser enviado com o código para /auth/2fa/verify.
+type TokenResponse struct {
+	Token          string `json:"token,omitempty"`
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	TokenType      string `json:"token_type,omitempty" example:"Bearer"`
+	ExpiresIn      int64  `json:"expires_in" example:"900"`
+	DoisFatores    bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
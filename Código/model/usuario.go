@@ -1,8 +1,115 @@
 package model
 
+// Papéis que um usuário pode ter. Administradores gerenciam qualquer usuário; membros apenas a si mesmos.
+const (
+	PapelAdmin  = "admin"
+	PapelMembro = "membro"
+)
+
+// Estados do ciclo de vida da conta. Apenas contas ativas fazem login e usam tokens ou API keys.
+const (
+	StatusPendente   = "pendente"
+	StatusAtivo      = "ativo"
+	StatusSuspenso   = "suspenso"
+	StatusDesativado = "desativado"
+)
+
+// Usuario é o registro gravado no banco. Não deve ser serializado nas respostas; use UsuarioResponse.
 type Usuario struct {
-	Id    int    `json:"id_usuario"`
-	Nome  string `json:"nome_usuario"`
-	Login string `json:"login_usuario"`
-	Senha string `json:"senha_usuario"`
+	Id     int    `json:"id_usuario"`
+	Nome   string `json:"nome_usuario"`
+	Login  string `json:"login_usuario"`
+	Senha  string `json:"-"`
+	Papel  string `json:"papel_usuario"`
+	Status string `json:"status_usuario"`
+}
+
+func (u Usuario) IsAtivo() bool {
+	return u.Status == StatusAtivo
+}
+
+// UsuarioRequest é o corpo aceito na criação de usuários
+type UsuarioRequest struct {
+	Nome   string `json:"nome_usuario" binding:"required" example:"João"`
+	Login  string `json:"login_usuario" binding:"required" example:"joao"`
+	Senha  string `json:"senha_usuario" binding:"required" example:"senha123"`
+	Papel  string `json:"papel_usuario" example:"membro"`
+	Status string `json:"status_usuario" example:"ativo"`
+}
+
+func (r UsuarioRequest) ToUsuario() Usuario {
+	return Usuario{Nome: r.Nome, Login: r.Login, Senha: r.Senha, Papel: r.Papel, Status: r.Status}
+}
+
+// UsuarioUpdateRequest é o corpo aceito na atualização de usuários. O papel só muda por
+// /usuario/{usuarioId}/papel e a senha por /auth/change-password, que confere a senha atual.
+type UsuarioUpdateRequest struct {
+	Nome  string `json:"nome_usuario" binding:"required" example:"João"`
+	Login string `json:"login_usuario" binding:"required" example:"joao"`
+}
+
+func (r UsuarioUpdateRequest) ToUsuario() Usuario {
+	return Usuario{Nome: r.Nome, Login: r.Login}
+}
+
+// UsuarioResponse é a representação pública do usuário, sem a senha
+type UsuarioResponse struct {
+	Id     int    `json:"id_usuario" example:"1"`
+	Nome   string `json:"nome_usuario" example:"João"`
+	Login  string `json:"login_usuario" example:"joao"`
+	Papel  string `json:"papel_usuario" example:"membro"`
+	Status string `json:"status_usuario" example:"ativo"`
+}
+
+func NewUsuarioResponse(usuario Usuario) UsuarioResponse {
+	return UsuarioResponse{
+		Id:     usuario.Id,
+		Nome:   usuario.Nome,
+		Login:  usuario.Login,
+		Papel:  usuario.Papel,
+		Status: usuario.Status,
+	}
+}
+
+func NewUsuarioResponses(usuarios []Usuario) []UsuarioResponse {
+	response := make([]UsuarioResponse, 0, len(usuarios))
+	for _, usuario := range usuarios {
+		response = append(response, NewUsuarioResponse(usuario))
+	}
+	return response
+}
+
+type PapelRequest struct {
+	Papel string `json:"papel_usuario" binding:"required" example:"admin"`
+}
+
+func IsPapelValido(papel string) bool {
+	return papel == PapelAdmin || papel == PapelMembro
+}
+
+type StatusRequest struct {
+	Status string `json:"status_usuario" binding:"required" example:"suspenso"`
+}
+
+// transicoesStatus lista, para cada estado, os estados para os quais a conta pode passar
+var transicoesStatus = map[string][]string{
+	StatusPendente:   {StatusAtivo, StatusDesativado},
+	StatusAtivo:      {StatusSuspenso, StatusDesativado},
+	StatusSuspenso:   {StatusAtivo, StatusDesativado},
+	StatusDesativado: {StatusAtivo},
+}
+
+func IsStatusValido(status string) bool {
+	_, ok := transicoesStatus[status]
+	return ok
+}
+
+// IsTransicaoValida indica se a conta pode passar do estado de para o estado para
+func IsTransicaoValida(de, para string) bool {
+	for _, status := range transicoesStatus[de] {
+		if status == para {
+			return true
+		}
+	}
+	return false
 }
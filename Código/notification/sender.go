@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Message é uma notificação enviada a um usuário, como o link de redefinição de senha
+type Message struct {
+	Destinatario string
+	Assunto      string
+	Corpo        string
+}
+
+// Sender entrega notificações aos usuários. Implementações de produção (e-mail, SMS) só
+// precisam satisfazer esta interface para serem usadas pelos usecases.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// WriterSender escreve as notificações em um io.Writer, útil em desenvolvimento e nos testes
+type WriterSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSender(w io.Writer) *WriterSender {
+	return &WriterSender{w: w}
+}
+
+func (ws *WriterSender) Send(msg Message) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	_, err := fmt.Fprintf(ws.w, "[%s] Para: %s\nAssunto: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.Destinatario, msg.Assunto, msg.Corpo)
+	return err
+}
+
+// FileSender acrescenta as notificações ao final de um arquivo
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (fs *FileSender) Send(msg Message) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	file, err := os.OpenFile(fs.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return NewWriterSender(file).Send(msg)
+}
+
+// NewSender grava as notificações no arquivo indicado ou, quando path é vazio, na saída padrão
+func NewSender(path string) Sender {
+	if path != "" {
+		return NewFileSender(path)
+	}
+	return NewWriterSender(os.Stdout)
+}
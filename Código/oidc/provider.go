@@ -0,0 +1,271 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrIDTokenInvalido = errors.New("ID token inválido")
+
+// Config descreve o cliente registrado no provedor de identidade
+type Config struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// NewConfig converte a seção oidc da configuração. Retorna nil quando o issuer não está
+// definido, deixando o login OIDC desativado.
+func NewConfig(cfg config.OIDCConfig) *Config {
+	if cfg.Issuer == "" {
+		return nil
+	}
+	return &Config{
+		Issuer:       cfg.Issuer,
+		ClientId:     cfg.ClientId,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+	}
+}
+
+// discovery é o subconjunto do documento /.well-known/openid-configuration usado pela API
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// IDTokenClaims são as claims do ID token usadas para identificar e provisionar o usuário
+type IDTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	AuthorizedParty   string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// Provider implementa o fluxo authorization code com PKCE contra um provedor OIDC. O documento
+// de discovery é buscado no primeiro uso, para que a API suba mesmo com o provedor fora do ar.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+	keysAt    time.Time
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	return &Provider{config: cfg, client: client}
+}
+
+func (p *Provider) Issuer() string {
+	return p.config.Issuer
+}
+
+// AuthCodeURL monta a URL de autorização do provedor com state, nonce e o desafio PKCE (S256)
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientId)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return d.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelo ID token, já validado contra o JWKS do provedor
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*IDTokenClaims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.config.ClientId)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var token struct {
+		IdToken string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("troca do código de autorização: %w", err)
+	}
+	if token.IdToken == "" {
+		return nil, errors.New("o provedor não retornou um id_token")
+	}
+
+	return p.VerifyIDToken(ctx, token.IdToken, nonce)
+}
+
+// VerifyIDToken valida assinatura, emissor, audiência, expiração e nonce do ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIDTokenInvalido, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sub ausente", ErrIDTokenInvalido)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce não confere", ErrIDTokenInvalido)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientId {
+		return nil, fmt.Errorf("%w: azp não confere", ErrIDTokenInvalido)
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var d discovery
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("discovery OIDC: %w", err)
+	}
+	if d.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("discovery OIDC: issuer %q diferente do configurado %q", d.Issuer, p.config.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, errors.New("discovery OIDC: documento incompleto")
+	}
+
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// Intervalo mínimo entre buscas do JWKS quando aparece um kid desconhecido, para que tokens
+// forjados não façam a API consultar o provedor a cada requisição
+const jwksRefreshInterval = time.Minute
+
+// key retorna a chave pública do kid, buscando o JWKS novamente quando o provedor rotaciona as chaves
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("kid %q desconhecido", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set jwkSet
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("JWKS OIDC: %w", err)
+	}
+
+	p.keys = set.publicKeys()
+	p.keysAt = time.Now()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("kid %q desconhecido", kid)
+}
+
+func (p *Provider) doJSON(req *http.Request, dest interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s respondeu %d: %s", req.URL.Host, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, dest)
+}
+
+// CodeChallenge calcula o desafio PKCE S256 do verificador (RFC 7636)
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"strings"
+	"time"
+)
+
+type ApiKeyRepository struct {
+	connection conexao
+}
+
+func NewApiKeyRepository(connection *sql.DB) ApiKeyRepository {
+	return ApiKeyRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+const apiKeyColumns = "id, usuario_id, nome, prefixo, key_hash, escopos, expira_em, ultimo_uso, revogada, criada_em"
+
+func (ar *ApiKeyRepository) CreateApiKey(apiKey model.ApiKey) (int, error) {
+	id, err := ar.connection.insert(
+		"INSERT INTO api_key (usuario_id, nome, prefixo, key_hash, escopos, expira_em, revogada, criada_em) VALUES (?, ?, ?, ?, ?, ?, 'N', ?)",
+		apiKey.UsuarioId,
+		apiKey.Nome,
+		apiKey.Prefixo,
+		apiKey.KeyHash,
+		strings.Join(apiKey.Escopos, ","),
+		apiKey.ExpiraEm,
+		apiKey.CriadaEm,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (ar *ApiKeyRepository) GetApiKeyByHash(keyHash string) (*model.ApiKey, error) {
+	row := ar.connection.QueryRow("SELECT "+apiKeyColumns+" FROM api_key WHERE key_hash = ?", keyHash)
+
+	apiKey, err := scanApiKey(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// GetApiKeysByUsuarioId lista as chaves não revogadas do usuário
+func (ar *ApiKeyRepository) GetApiKeysByUsuarioId(usuarioId int) ([]model.ApiKey, error) {
+	rows, err := ar.connection.Query("SELECT "+apiKeyColumns+" FROM api_key WHERE usuario_id = ? AND revogada = 'N' ORDER BY id", usuarioId)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []model.ApiKey{}
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+// RevokeApiKey revoga a chave do usuário. Retorna sql.ErrNoRows se ela não existir, for de
+// outro usuário ou já estiver revogada.
+func (ar *ApiKeyRepository) RevokeApiKey(id int, usuarioId int) error {
+	result, err := ar.connection.Exec("UPDATE api_key SET revogada = 'S' WHERE id = ? AND usuario_id = ? AND revogada = 'N'", id, usuarioId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (ar *ApiKeyRepository) UpdateUltimoUso(id int, ultimoUso time.Time) error {
+	_, err := ar.connection.Exec("UPDATE api_key SET ultimo_uso = ? WHERE id = ?", ultimoUso, id)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanApiKey(row scanner) (*model.ApiKey, error) {
+	var apiKey model.ApiKey
+	var escopos string
+	var expiraEm, ultimoUso sql.NullTime
+	err := row.Scan(
+		&apiKey.Id,
+		&apiKey.UsuarioId,
+		&apiKey.Nome,
+		&apiKey.Prefixo,
+		&apiKey.KeyHash,
+		&escopos,
+		&expiraEm,
+		&ultimoUso,
+		&apiKey.Revogada,
+		&apiKey.CriadaEm,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey.Escopos = []string{}
+	if escopos != "" {
+		apiKey.Escopos = strings.Split(escopos, ",")
+	}
+	if expiraEm.Valid {
+		apiKey.ExpiraEm = &expiraEm.Time
+	}
+	if ultimoUso.Valid {
+		apiKey.UltimoUso = &ultimoUso.Time
+	}
+
+	return &apiKey, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Erros de restrição do banco, iguais em todos os backends. O erro original do driver
+// continua na mensagem.
+var (
+	ErrRegistroDuplicado  = errors.New("já existe um registro com o mesmo valor único")
+	ErrReferenciaInvalida = errors.New("o registro referenciado não existe")
+)
+
+// conexao é o *sql.DB usado pelos repositórios. As consultas são escritas com "?", o marcador
+// de parâmetro do MySQL e do SQLite; no PostgreSQL os parâmetros são numerados ($1, $2, ...)
+// e conexao faz a tradução antes de enviar a consulta.
+type conexao struct {
+	*sql.DB
+	postgres bool
+	mysql    bool
+}
+
+func novaConexao(db *sql.DB) conexao {
+	_, postgres := db.Driver().(*pq.Driver)
+	_, ehMySQL := db.Driver().(*mysql.MySQLDriver)
+	return conexao{DB: db, postgres: postgres, mysql: ehMySQL}
+}
+
+func (c conexao) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.DB.Query(c.rebind(query), args...)
+}
+
+func (c conexao) QueryRow(query string, args ...any) *sql.Row {
+	return c.DB.QueryRow(c.rebind(query), args...)
+}
+
+func (c conexao) Exec(query string, args ...any) (sql.Result, error) {
+	return c.DB.Exec(c.rebind(query), args...)
+}
+
+func (c conexao) Prepare(query string) (*sql.Stmt, error) {
+	return c.DB.Prepare(c.rebind(query))
+}
+
+// rebind numera os "?" da consulta no PostgreSQL. Interrogações dentro de literais entre
+// aspas simples não são parâmetros e ficam como estão.
+func (c conexao) rebind(query string) string {
+	if !c.postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	literal := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			literal = !literal
+		case r == '?' && !literal:
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// insert executa o INSERT e devolve o id gerado. O driver do PostgreSQL não implementa
+// LastInsertId; lá o id é pedido ao próprio INSERT com RETURNING.
+func (c conexao) insert(query string, args ...any) (int, error) {
+	if c.postgres {
+		var id int
+		err := c.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, erroDoBanco(err)
+	}
+
+	result, err := c.Exec(query, args...)
+	if err != nil {
+		return 0, erroDoBanco(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// erroDoBanco traduz as violações de unicidade e de chave estrangeira de cada driver para
+// ErrRegistroDuplicado e ErrReferenciaInvalida. Os demais erros são devolvidos como vieram.
+func erroDoBanco(err error) error {
+	var pqErr *pq.Error
+	var mysqlErr *mysql.MySQLError
+	var sqliteErr sqlite3.Error
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &pqErr):
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			return fmt.Errorf("%w: %v", ErrRegistroDuplicado, err)
+		case "23503": // foreign_key_violation
+			return fmt.Errorf("%w: %v", ErrReferenciaInvalida, err)
+		}
+	case errors.As(err, &mysqlErr):
+		switch mysqlErr.Number {
+		case 1062: // ER_DUP_ENTRY
+			return fmt.Errorf("%w: %v", ErrRegistroDuplicado, err)
+		case 1452: // ER_NO_REFERENCED_ROW_2
+			return fmt.Errorf("%w: %v", ErrReferenciaInvalida, err)
+		}
+	case errors.As(err, &sqliteErr):
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return fmt.Errorf("%w: %v", ErrRegistroDuplicado, err)
+		case sqlite3.ErrConstraintForeignKey:
+			return fmt.Errorf("%w: %v", ErrReferenciaInvalida, err)
+		}
+	}
+	return err
+}
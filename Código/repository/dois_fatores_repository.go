@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"time"
+)
+
+type DoisFatoresRepository struct {
+	connection conexao
+}
+
+func NewDoisFatoresRepository(connection *sql.DB) DoisFatoresRepository {
+	return DoisFatoresRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (dr *DoisFatoresRepository) GetDoisFatores(usuarioId int) (*model.DoisFatores, error) {
+	row := dr.connection.QueryRow("SELECT usuario_id, segredo, confirmado, ultimo_passo FROM usuario_2fa WHERE usuario_id = ?", usuarioId)
+
+	var doisFatores model.DoisFatores
+	err := row.Scan(
+		&doisFatores.UsuarioId,
+		&doisFatores.Segredo,
+		&doisFatores.Confirmado,
+		&doisFatores.UltimoPasso,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &doisFatores, nil
+}
+
+// SaveSegredo grava um novo segredo ainda não confirmado, substituindo um cadastro pendente
+func (dr *DoisFatoresRepository) SaveSegredo(usuarioId int, segredo string) error {
+	result, err := dr.connection.Exec(
+		"UPDATE usuario_2fa SET segredo = ?, confirmado = 'N', ultimo_passo = 0 WHERE usuario_id = ?",
+		segredo,
+		usuarioId,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	_, err = dr.connection.Exec(
+		"INSERT INTO usuario_2fa (usuario_id, segredo, confirmado, ultimo_passo) VALUES (?, ?, 'N', 0)",
+		usuarioId,
+		segredo,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// UseCodigo registra o passo do código aceito e confirma o cadastro. Retorna false se um
+// código do mesmo passo ou de um passo posterior já tiver sido usado.
+func (dr *DoisFatoresRepository) UseCodigo(usuarioId int, passo int64) (bool, error) {
+	result, err := dr.connection.Exec(
+		"UPDATE usuario_2fa SET ultimo_passo = ?, confirmado = 'S' WHERE usuario_id = ? AND ultimo_passo < ?",
+		passo,
+		usuarioId,
+		passo,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ReplaceCodigosRecuperacao descarta os códigos de recuperação do usuário e grava os novos
+func (dr *DoisFatoresRepository) ReplaceCodigosRecuperacao(usuarioId int, hashes []string) error {
+	tx, err := dr.connection.Begin()
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(dr.connection.rebind("DELETE FROM codigo_recuperacao WHERE usuario_id = ?"), usuarioId); err != nil {
+		fmt.Println(err)
+		return err
+	}
+	for _, hash := range hashes {
+		_, err := tx.Exec(dr.connection.rebind("INSERT INTO codigo_recuperacao (usuario_id, codigo_hash) VALUES (?, ?)"), usuarioId, hash)
+		if err != nil {
+			fmt.Println(err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UseCodigoRecuperacao consome o código de recuperação. Retorna false se ele não existir ou já tiver sido usado.
+func (dr *DoisFatoresRepository) UseCodigoRecuperacao(usuarioId int, hash string, usadoEm time.Time) (bool, error) {
+	result, err := dr.connection.Exec(
+		"UPDATE codigo_recuperacao SET usado_em = ? WHERE usuario_id = ? AND codigo_hash = ? AND usado_em IS NULL",
+		usadoEm,
+		usuarioId,
+		hash,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
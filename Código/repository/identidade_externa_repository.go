@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+)
+
+type IdentidadeExternaRepository struct {
+	connection conexao
+}
+
+func NewIdentidadeExternaRepository(connection *sql.DB) IdentidadeExternaRepository {
+	return IdentidadeExternaRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (ir *IdentidadeExternaRepository) GetIdentidade(issuer, subject string) (*model.IdentidadeExterna, error) {
+	row := ir.connection.QueryRow(
+		"SELECT usuario_id, issuer, subject, criada_em FROM usuario_identidade WHERE issuer = ? AND subject = ?",
+		issuer,
+		subject,
+	)
+
+	var identidade model.IdentidadeExterna
+	err := row.Scan(
+		&identidade.UsuarioId,
+		&identidade.Issuer,
+		&identidade.Subject,
+		&identidade.CriadaEm,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+
+	return &identidade, nil
+}
+
+func (ir *IdentidadeExternaRepository) CreateIdentidade(identidade model.IdentidadeExterna) error {
+	_, err := ir.connection.Exec(
+		"INSERT INTO usuario_identidade (usuario_id, issuer, subject, criada_em) VALUES (?, ?, ?, ?)",
+		identidade.UsuarioId,
+		identidade.Issuer,
+		identidade.Subject,
+		identidade.CriadaEm,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return erroDoBanco(err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"time"
+)
+
+type RefreshTokenRepository struct {
+	connection conexao
+}
+
+func NewRefreshTokenRepository(connection *sql.DB) RefreshTokenRepository {
+	return RefreshTokenRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (rr *RefreshTokenRepository) CreateRefreshToken(refreshToken model.RefreshToken) (int, error) {
+	id, err := rr.connection.insert(
+		"INSERT INTO refresh_token (token_hash, usuario_id, familia, expira_em, revogado) VALUES (?, ?, ?, ?, 'N')",
+		refreshToken.TokenHash,
+		refreshToken.UsuarioId,
+		refreshToken.Familia,
+		refreshToken.ExpiraEm,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (rr *RefreshTokenRepository) GetRefreshTokenByHash(tokenHash string) (*model.RefreshToken, error) {
+	query := "SELECT id, token_hash, usuario_id, familia, expira_em, usado_em, revogado FROM refresh_token WHERE token_hash = ?"
+	row := rr.connection.QueryRow(query, tokenHash)
+
+	var refreshToken model.RefreshToken
+	var usadoEm sql.NullTime
+	err := row.Scan(
+		&refreshToken.Id,
+		&refreshToken.TokenHash,
+		&refreshToken.UsuarioId,
+		&refreshToken.Familia,
+		&refreshToken.ExpiraEm,
+		&usadoEm,
+		&refreshToken.Revogado,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+
+	if usadoEm.Valid {
+		refreshToken.UsadoEm = &usadoEm.Time
+	}
+
+	return &refreshToken, nil
+}
+
+// MarkRefreshTokenUsed marca o token como usado apenas se ele ainda não tiver sido usado.
+// Retorna false quando outra requisição já o consumiu.
+func (rr *RefreshTokenRepository) MarkRefreshTokenUsed(id int, usadoEm time.Time) (bool, error) {
+	result, err := rr.connection.Exec(
+		"UPDATE refresh_token SET usado_em = ? WHERE id = ? AND usado_em IS NULL AND revogado = 'N'",
+		usadoEm,
+		id,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (rr *RefreshTokenRepository) RevokeFamilia(familia string) error {
+	_, err := rr.connection.Exec("UPDATE refresh_token SET revogado = 'S' WHERE familia = ?", familia)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUsuario revoga todos os refresh tokens do usuário, encerrando todas as suas sessões
+func (rr *RefreshTokenRepository) RevokeUsuario(usuarioId int) error {
+	_, err := rr.connection.Exec("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ?", usuarioId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeOutrasFamilias revoga os refresh tokens do usuário, exceto os da família informada
+func (rr *RefreshTokenRepository) RevokeOutrasFamilias(usuarioId int, familia string) error {
+	_, err := rr.connection.Exec("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ? AND familia <> ?", usuarioId, familia)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (rr *RefreshTokenRepository) DeleteExpiredRefreshTokens(now time.Time) (int64, error) {
+	result, err := rr.connection.Exec("DELETE FROM refresh_token WHERE expira_em <= ?", now)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
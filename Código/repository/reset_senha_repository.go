@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"time"
+)
+
+type ResetSenhaRepository struct {
+	connection conexao
+}
+
+func NewResetSenhaRepository(connection *sql.DB) ResetSenhaRepository {
+	return ResetSenhaRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (rr *ResetSenhaRepository) CreateResetSenha(reset model.ResetSenha) (int, error) {
+	id, err := rr.connection.insert(
+		"INSERT INTO reset_senha (token_hash, usuario_id, expira_em) VALUES (?, ?, ?)",
+		reset.TokenHash,
+		reset.UsuarioId,
+		reset.ExpiraEm,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (rr *ResetSenhaRepository) GetResetSenhaByHash(tokenHash string) (*model.ResetSenha, error) {
+	row := rr.connection.QueryRow("SELECT id, token_hash, usuario_id, expira_em, usado_em FROM reset_senha WHERE token_hash = ?", tokenHash)
+
+	var reset model.ResetSenha
+	var usadoEm sql.NullTime
+	err := row.Scan(
+		&reset.Id,
+		&reset.TokenHash,
+		&reset.UsuarioId,
+		&reset.ExpiraEm,
+		&usadoEm,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+
+	if usadoEm.Valid {
+		reset.UsadoEm = &usadoEm.Time
+	}
+
+	return &reset, nil
+}
+
+// MarkResetSenhaUsado marca o token como usado apenas se ele ainda não tiver sido usado.
+// Retorna false quando outra requisição já o consumiu.
+func (rr *ResetSenhaRepository) MarkResetSenhaUsado(id int, usadoEm time.Time) (bool, error) {
+	result, err := rr.connection.Exec(
+		"UPDATE reset_senha SET usado_em = ? WHERE id = ? AND usado_em IS NULL",
+		usadoEm,
+		id,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// ExisteResetSenhaPendente indica se o usuário tem um token ainda não usado que expira depois de expiraApos
+func (rr *ResetSenhaRepository) ExisteResetSenhaPendente(usuarioId int, expiraApos time.Time) (bool, error) {
+	var pendentes int
+	err := rr.connection.QueryRow(
+		"SELECT COUNT(*) FROM reset_senha WHERE usuario_id = ? AND usado_em IS NULL AND expira_em > ?",
+		usuarioId,
+		expiraApos,
+	).Scan(&pendentes)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	return pendentes > 0, nil
+}
+
+// DeleteResetSenhasPendentes invalida os tokens ainda não usados do usuário
+func (rr *ResetSenhaRepository) DeleteResetSenhasPendentes(usuarioId int) error {
+	_, err := rr.connection.Exec("DELETE FROM reset_senha WHERE usuario_id = ? AND usado_em IS NULL", usuarioId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (rr *ResetSenhaRepository) DeleteExpiredResetSenhas(now time.Time) (int64, error) {
+	result, err := rr.connection.Exec("DELETE FROM reset_senha WHERE expira_em <= ?", now)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"time"
+)
+
+type SessaoRepository struct {
+	connection conexao
+}
+
+func NewSessaoRepository(connection *sql.DB) SessaoRepository {
+	return SessaoRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (sr *SessaoRepository) CreateSessao(sessao model.Sessao) error {
+	_, err := sr.connection.Exec(
+		"INSERT INTO sessao (id, usuario_id, dispositivo, ip, criada_em, ultimo_uso) VALUES (?, ?, ?, ?, ?, ?)",
+		sessao.Id,
+		sessao.UsuarioId,
+		sessao.Dispositivo,
+		sessao.IP,
+		sessao.CriadaEm,
+		sessao.UltimoUso,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (sr *SessaoRepository) GetSessao(id string) (*model.Sessao, error) {
+	row := sr.connection.QueryRow(
+		"SELECT id, usuario_id, dispositivo, ip, criada_em, ultimo_uso, encerrada_em FROM sessao WHERE id = ?",
+		id,
+	)
+
+	sessao, err := scanSessao(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		fmt.Println(err)
+		return nil, err
+	}
+	return sessao, nil
+}
+
+// GetSessoesAtivas lista as sessões não encerradas do usuário, da usada mais recentemente para a mais antiga
+func (sr *SessaoRepository) GetSessoesAtivas(usuarioId int) ([]model.Sessao, error) {
+	rows, err := sr.connection.Query(
+		"SELECT id, usuario_id, dispositivo, ip, criada_em, ultimo_uso, encerrada_em FROM sessao WHERE usuario_id = ? AND encerrada_em IS NULL ORDER BY ultimo_uso DESC",
+		usuarioId,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessoes := []model.Sessao{}
+	for rows.Next() {
+		sessao, err := scanSessao(rows)
+		if err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+		sessoes = append(sessoes, *sessao)
+	}
+
+	return sessoes, rows.Err()
+}
+
+func (sr *SessaoRepository) TouchSessao(id string, ultimoUso time.Time) error {
+	_, err := sr.connection.Exec("UPDATE sessao SET ultimo_uso = ? WHERE id = ? AND encerrada_em IS NULL", ultimoUso, id)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (sr *SessaoRepository) EncerrarSessao(id string, encerradaEm time.Time) error {
+	_, err := sr.connection.Exec("UPDATE sessao SET encerrada_em = ? WHERE id = ? AND encerrada_em IS NULL", encerradaEm, id)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+func (sr *SessaoRepository) EncerrarSessoesDoUsuario(usuarioId int, encerradaEm time.Time) error {
+	_, err := sr.connection.Exec("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND encerrada_em IS NULL", encerradaEm, usuarioId)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+// EncerrarOutrasSessoes encerra as sessões do usuário, exceto a informada
+func (sr *SessaoRepository) EncerrarOutrasSessoes(usuarioId int, id string, encerradaEm time.Time) error {
+	_, err := sr.connection.Exec(
+		"UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND id <> ? AND encerrada_em IS NULL",
+		encerradaEm,
+		usuarioId,
+		id,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	return nil
+}
+
+// DeleteSessoesEncerradas remove as sessões encerradas e as abandonadas, criadas antes de limite
+// e cujo último refresh token expirou antes dele. Tokens de acesso de sessões removidas continuam
+// sendo recusados, já que a sessão não existe mais.
+func (sr *SessaoRepository) DeleteSessoesEncerradas(limite time.Time) (int64, error) {
+	result, err := sr.connection.Exec(
+		"DELETE FROM sessao WHERE encerrada_em IS NOT NULL OR (criada_em < ? AND NOT EXISTS (SELECT 1 FROM refresh_token WHERE refresh_token.familia = sessao.id AND refresh_token.expira_em >= ?))",
+		limite,
+		limite,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func scanSessao(row scanner) (*model.Sessao, error) {
+	var sessao model.Sessao
+	var encerradaEm sql.NullTime
+	err := row.Scan(
+		&sessao.Id,
+		&sessao.UsuarioId,
+		&sessao.Dispositivo,
+		&sessao.IP,
+		&sessao.CriadaEm,
+		&sessao.UltimoUso,
+		&encerradaEm,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if encerradaEm.Valid {
+		sessao.EncerradaEm = &encerradaEm.Time
+	}
+	return &sessao, nil
+}
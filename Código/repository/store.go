@@ -0,0 +1,38 @@
+package repository
+
+import "go-api/model"
+
+// UsuarioStore é o armazenamento de usuários usado pelos usecases. UsuarioRepository o
+// implementa sobre o banco configurado e UsuarioMemoryStore em memória. As buscas retornam
+// nil, nil quando o usuário não existe e as alterações, sql.ErrNoRows; logins repetidos
+// resultam em ErrRegistroDuplicado.
+type UsuarioStore interface {
+	GetUsuarios() ([]model.Usuario, error)
+	CreateUsuario(usuario model.Usuario) (int, error)
+	GetUsuarioById(id_usuario int) (*model.Usuario, error)
+	GetUsuarioByLogin(login string) (*model.Usuario, error)
+	UpdateSenhaById(id_usuario int, senha string) error
+	UpdatePerfilById(id_usuario int, nome, login string) error
+	UpdatePapelById(id_usuario int, papel string) error
+	UpdateStatusById(id_usuario int, status string) error
+	SoftDeleteUsuarioById(id_usuario int) error
+	DeleteUsuarioById(id_usuario int) error
+}
+
+// TarefaStore é o armazenamento de tarefas usado pelos usecases, com as mesmas convenções de
+// UsuarioStore. Responsáveis inexistentes resultam em ErrReferenciaInvalida.
+type TarefaStore interface {
+	GetTarefas() ([]model.Tarefa, error)
+	CreateTarefa(tarefa model.Tarefa) (int, error)
+	GetTarefaById(id_tarefa int) (*model.Tarefa, error)
+	GetTarefasByUsuarioId(usuarioId string) ([]model.Tarefa, error)
+	UpdateTarefaById(id_tarefa int, tarefa *model.Tarefa) error
+	SoftDeleteTarefaById(id_tarefa int) error
+}
+
+var (
+	_ UsuarioStore = (*UsuarioRepository)(nil)
+	_ UsuarioStore = (*UsuarioMemoryStore)(nil)
+	_ TarefaStore  = (*TarefaRepository)(nil)
+	_ TarefaStore  = (*TarefaMemoryStore)(nil)
+)
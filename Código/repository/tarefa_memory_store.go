@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"go-api/model"
+	"strconv"
+	"sync"
+)
+
+// tarefaMemoria é a tarefa guardada por TarefaMemoryStore com a marca de remoção, que no
+// banco é a coluna ativo
+type tarefaMemoria struct {
+	model.Tarefa
+	ativa bool
+}
+
+// TarefaMemoryStore guarda as tarefas em memória. Os responsáveis são conferidos em
+// usuarios, como faz a chave estrangeira da tabela tarefa. É seguro para uso concorrente.
+type TarefaMemoryStore struct {
+	mu       sync.RWMutex
+	ultimoId int
+	tarefas  map[int]tarefaMemoria
+	usuarios *UsuarioMemoryStore
+}
+
+func NewTarefaMemoryStore(usuarios *UsuarioMemoryStore) *TarefaMemoryStore {
+	return &TarefaMemoryStore{
+		tarefas:  map[int]tarefaMemoria{},
+		usuarios: usuarios,
+	}
+}
+
+// GetTarefas lista todas as tarefas em ordem de id, inclusive as removidas, como a consulta
+// de TarefaRepository
+func (s *TarefaMemoryStore) GetTarefas() ([]model.Tarefa, error) {
+	return s.listar(func(tarefa tarefaMemoria) bool { return true }), nil
+}
+
+// CreateTarefa retorna ErrReferenciaInvalida quando o usuário responsável não existe
+func (s *TarefaMemoryStore) CreateTarefa(tarefa model.Tarefa) (int, error) {
+	if !s.responsavelExiste(tarefa.UsuarioResp) {
+		return 0, ErrReferenciaInvalida
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ultimoId++
+	tarefa.Id = s.ultimoId
+	s.tarefas[tarefa.Id] = tarefaMemoria{Tarefa: tarefa, ativa: true}
+	return tarefa.Id, nil
+}
+
+func (s *TarefaMemoryStore) GetTarefaById(id_tarefa int) (*model.Tarefa, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tarefa, ok := s.tarefas[id_tarefa]
+	if !ok {
+		return nil, nil
+	}
+	return &tarefa.Tarefa, nil
+}
+
+// GetTarefasByUsuarioId lista as tarefas não removidas do responsável
+func (s *TarefaMemoryStore) GetTarefasByUsuarioId(usuarioId string) ([]model.Tarefa, error) {
+	return s.listar(func(tarefa tarefaMemoria) bool {
+		return tarefa.ativa && tarefa.UsuarioResp == usuarioId
+	}), nil
+}
+
+func (s *TarefaMemoryStore) UpdateTarefaById(id_tarefa int, tarefa *model.Tarefa) error {
+	if !s.responsavelExiste(tarefa.UsuarioResp) {
+		return ErrReferenciaInvalida
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atual, ok := s.tarefas[id_tarefa]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	atual.Nome = tarefa.Nome
+	atual.Conteudo = tarefa.Conteudo
+	atual.UsuarioResp = tarefa.UsuarioResp
+	atual.Finalizado = tarefa.Finalizado
+	s.tarefas[id_tarefa] = atual
+	return nil
+}
+
+func (s *TarefaMemoryStore) SoftDeleteTarefaById(id_tarefa int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tarefa, ok := s.tarefas[id_tarefa]
+	if !ok || !tarefa.ativa {
+		return sql.ErrNoRows
+	}
+	tarefa.ativa = false
+	s.tarefas[id_tarefa] = tarefa
+	return nil
+}
+
+func (s *TarefaMemoryStore) listar(filtro func(tarefa tarefaMemoria) bool) []model.Tarefa {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tarefaList []model.Tarefa
+	for id := 1; id <= s.ultimoId; id++ {
+		tarefa, ok := s.tarefas[id]
+		if ok && filtro(tarefa) {
+			tarefaList = append(tarefaList, tarefa.Tarefa)
+		}
+	}
+	return tarefaList
+}
+
+func (s *TarefaMemoryStore) responsavelExiste(usuarioResp string) bool {
+	id, err := strconv.Atoi(usuarioResp)
+	return err == nil && s.usuarios.existe(id)
+}
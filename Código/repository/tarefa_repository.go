@@ -7,12 +7,12 @@ import (
 )
 
 type TarefaRepository struct {
-	connection *sql.DB
+	connection conexao
 }
 
-func NewTarefaRepository(connection *sql.DB) TarefaRepository {
-	return TarefaRepository{
-		connection: connection,
+func NewTarefaRepository(connection *sql.DB) *TarefaRepository {
+	return &TarefaRepository{
+		connection: novaConexao(connection),
 	}
 }
 
@@ -45,8 +45,9 @@ func (tr *TarefaRepository) GetTarefas() ([]model.Tarefa, error) {
 	return tarefaList, nil
 }
 
+// CreateTarefa retorna ErrReferenciaInvalida quando o usuário responsável não existe
 func (tr *TarefaRepository) CreateTarefa(tarefa model.Tarefa) (int, error) {
-	result, err := tr.connection.Exec(
+	id, err := tr.connection.insert(
 		"INSERT INTO tarefa (nome, conteudo, usuario_responsavel, finalizado) VALUES (?, ?, ?, ?)",
 		tarefa.Nome,
 		tarefa.Conteudo,
@@ -58,13 +59,7 @@ func (tr *TarefaRepository) CreateTarefa(tarefa model.Tarefa) (int, error) {
 		return 0, err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		fmt.Println(err)
-		return 0, err
-	}
-
-	return int(id), nil
+	return id, nil
 }
 
 func (tr *TarefaRepository) GetTarefaById(id_tarefa int) (*model.Tarefa, error) {
@@ -116,7 +111,7 @@ func (tr *TarefaRepository) UpdateTarefaById(id_tarefa int, tarefa *model.Tarefa
 
 	if err != nil {
 		fmt.Println(err)
-		return err
+		return erroDoBanco(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"go-api/model"
+	"strings"
+	"time"
+)
+
+type TentativaLoginRepository struct {
+	connection conexao
+}
+
+func NewTentativaLoginRepository(connection *sql.DB) TentativaLoginRepository {
+	return TentativaLoginRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (tr *TentativaLoginRepository) GetTentativas(chaves ...string) ([]model.TentativaLogin, error) {
+	if len(chaves) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(chaves))
+	for i, chave := range chaves {
+		args[i] = chave
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(chaves)), ", ")
+
+	rows, err := tr.connection.Query(
+		"SELECT chave, falhas, bloqueado_ate, atualizado_em FROM tentativa_login WHERE chave IN ("+placeholders+")",
+		args...,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tentativas []model.TentativaLogin
+	for rows.Next() {
+		var tentativa model.TentativaLogin
+		err := rows.Scan(
+			&tentativa.Chave,
+			&tentativa.Falhas,
+			&tentativa.BloqueadoAte,
+			&tentativa.AtualizadoEm,
+		)
+		if err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+		tentativas = append(tentativas, tentativa)
+	}
+
+	return tentativas, rows.Err()
+}
+
+// IncrementaFalhas soma uma falha à chave, criando-a quando ainda não existe, e retorna o total
+// de falhas. A contagem recomeça quando a última falha é anterior a inicioJanela. O incremento
+// é feito pelo banco em uma única instrução, para que falhas simultâneas não se percam, e o
+// total é lido na mesma transação, enquanto a linha continua travada.
+func (tr *TentativaLoginRepository) IncrementaFalhas(chave string, now time.Time, inicioJanela time.Time) (int, error) {
+	upsert := "INSERT INTO tentativa_login (chave, falhas, bloqueado_ate, atualizado_em) VALUES (?, 1, ?, ?) " +
+		"ON CONFLICT (chave) DO UPDATE SET " +
+		"falhas = CASE WHEN tentativa_login.atualizado_em < ? THEN 1 ELSE tentativa_login.falhas + 1 END, " +
+		"atualizado_em = excluded.atualizado_em"
+	if tr.connection.mysql {
+		upsert = "INSERT INTO tentativa_login (chave, falhas, bloqueado_ate, atualizado_em) VALUES (?, 1, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE " +
+			"falhas = CASE WHEN atualizado_em < ? THEN 1 ELSE falhas + 1 END, " +
+			"atualizado_em = VALUES(atualizado_em)"
+	}
+
+	tx, err := tr.connection.Begin()
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(tr.connection.rebind(upsert), chave, now, now, inicioJanela); err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	var falhas int
+	err = tx.QueryRow(tr.connection.rebind("SELECT falhas FROM tentativa_login WHERE chave = ?"), chave).Scan(&falhas)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return falhas, tx.Commit()
+}
+
+// ProrrogaBloqueio bloqueia a chave até ate. Um bloqueio mais longo, gravado por uma falha
+// simultânea com contagem maior, é mantido.
+func (tr *TentativaLoginRepository) ProrrogaBloqueio(chave string, ate time.Time) error {
+	_, err := tr.connection.Exec(
+		"UPDATE tentativa_login SET bloqueado_ate = ? WHERE chave = ? AND bloqueado_ate < ?",
+		ate,
+		chave,
+		ate,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tr *TentativaLoginRepository) DeleteTentativa(chave string) (bool, error) {
+	result, err := tr.connection.Exec("DELETE FROM tentativa_login WHERE chave = ?", chave)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteTentativasExpiradas remove as chaves sem bloqueio ativo e sem falhas recentes
+func (tr *TentativaLoginRepository) DeleteTentativasExpiradas(now time.Time, atualizadoAntesDe time.Time) (int64, error) {
+	result, err := tr.connection.Exec(
+		"DELETE FROM tentativa_login WHERE bloqueado_ate <= ? AND atualizado_em <= ?",
+		now,
+		atualizadoAntesDe,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
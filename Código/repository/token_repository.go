@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type TokenRepository struct {
+	connection conexao
+}
+
+func NewTokenRepository(connection *sql.DB) TokenRepository {
+	return TokenRepository{
+		connection: novaConexao(connection),
+	}
+}
+
+func (tr *TokenRepository) RevokeToken(jti string, expiraEm time.Time) error {
+	_, err := tr.connection.Exec(
+		"INSERT INTO token_revogado (jti, expira_em) VALUES (?, ?)",
+		jti,
+		expiraEm,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (tr *TokenRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int
+	err := tr.connection.QueryRow("SELECT COUNT(*) FROM token_revogado WHERE jti = ?", jti).Scan(&count)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (tr *TokenRepository) GetRevokedTokens(now time.Time) (map[string]time.Time, error) {
+	rows, err := tr.connection.Query("SELECT jti, expira_em FROM token_revogado WHERE expira_em > ?", now)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]time.Time)
+	for rows.Next() {
+		var jti string
+		var expiraEm time.Time
+		if err := rows.Scan(&jti, &expiraEm); err != nil {
+			fmt.Println(err)
+			return nil, err
+		}
+		tokens[jti] = expiraEm
+	}
+
+	return tokens, rows.Err()
+}
+
+func (tr *TokenRepository) DeleteExpiredTokens(now time.Time) (int64, error) {
+	result, err := tr.connection.Exec("DELETE FROM token_revogado WHERE expira_em <= ?", now)
+	if err != nil {
+		fmt.Println(err)
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"go-api/model"
+	"sync"
+)
+
+// UsuarioMemoryStore guarda os usuários em memória, para demonstrações e testes rápidos.
+// Segue as mesmas regras da tabela usuario: login único, ids crescentes e desativação em vez
+// de remoção. É seguro para uso concorrente.
+type UsuarioMemoryStore struct {
+	mu       sync.RWMutex
+	ultimoId int
+	usuarios map[int]model.Usuario
+}
+
+func NewUsuarioMemoryStore() *UsuarioMemoryStore {
+	return &UsuarioMemoryStore{
+		usuarios: map[int]model.Usuario{},
+	}
+}
+
+// GetUsuarios lista os usuários que não foram desativados, em ordem de id
+func (s *UsuarioMemoryStore) GetUsuarios() ([]model.Usuario, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var usuarioList []model.Usuario
+	for id := 1; id <= s.ultimoId; id++ {
+		usuario, ok := s.usuarios[id]
+		if ok && usuario.Status != model.StatusDesativado {
+			usuarioList = append(usuarioList, usuario)
+		}
+	}
+	return usuarioList, nil
+}
+
+// CreateUsuario retorna ErrRegistroDuplicado quando o login já pertence a outro usuário
+func (s *UsuarioMemoryStore) CreateUsuario(usuario model.Usuario) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loginEmUso(usuario.Login, 0) {
+		return 0, ErrRegistroDuplicado
+	}
+
+	s.ultimoId++
+	usuario.Id = s.ultimoId
+	s.usuarios[usuario.Id] = usuario
+	return usuario.Id, nil
+}
+
+func (s *UsuarioMemoryStore) GetUsuarioById(id_usuario int) (*model.Usuario, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usuario, ok := s.usuarios[id_usuario]
+	if !ok {
+		return nil, nil
+	}
+	return &usuario, nil
+}
+
+// GetUsuarioByLogin ignora usuários desativados, que deixam de existir para o login
+func (s *UsuarioMemoryStore) GetUsuarioByLogin(login string) (*model.Usuario, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, usuario := range s.usuarios {
+		if usuario.Login == login && usuario.Status != model.StatusDesativado {
+			return &usuario, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *UsuarioMemoryStore) UpdateSenhaById(id_usuario int, senha string) error {
+	return s.update(id_usuario, func(atual *model.Usuario) error {
+		atual.Senha = senha
+		return nil
+	})
+}
+
+func (s *UsuarioMemoryStore) UpdatePerfilById(id_usuario int, nome, login string) error {
+	return s.update(id_usuario, func(atual *model.Usuario) error {
+		if s.loginEmUso(login, id_usuario) {
+			return ErrRegistroDuplicado
+		}
+		atual.Nome = nome
+		atual.Login = login
+		return nil
+	})
+}
+
+func (s *UsuarioMemoryStore) UpdatePapelById(id_usuario int, papel string) error {
+	return s.update(id_usuario, func(atual *model.Usuario) error {
+		atual.Papel = papel
+		return nil
+	})
+}
+
+func (s *UsuarioMemoryStore) UpdateStatusById(id_usuario int, status string) error {
+	return s.update(id_usuario, func(atual *model.Usuario) error {
+		atual.Status = status
+		return nil
+	})
+}
+
+// SoftDeleteUsuarioById desativa o usuário a partir de qualquer outro estado
+func (s *UsuarioMemoryStore) SoftDeleteUsuarioById(id_usuario int) error {
+	return s.update(id_usuario, func(atual *model.Usuario) error {
+		if atual.Status == model.StatusDesativado {
+			return sql.ErrNoRows
+		}
+		atual.Status = model.StatusDesativado
+		return nil
+	})
+}
+
+// DeleteUsuarioById remove o usuário de vez, liberando o login
+func (s *UsuarioMemoryStore) DeleteUsuarioById(id_usuario int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.usuarios[id_usuario]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(s.usuarios, id_usuario)
+	return nil
+}
+
+// update aplica alterar ao usuário sob o lock de escrita. O usuário só é gravado se alterar
+// não retornar erro.
+func (s *UsuarioMemoryStore) update(id_usuario int, alterar func(atual *model.Usuario) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usuario, ok := s.usuarios[id_usuario]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := alterar(&usuario); err != nil {
+		return err
+	}
+	s.usuarios[id_usuario] = usuario
+	return nil
+}
+
+// loginEmUso diz se o login pertence a um usuário diferente de id_usuario. Como na
+// restrição UNIQUE da tabela, usuários desativados também ocupam o login. Exige o lock.
+func (s *UsuarioMemoryStore) loginEmUso(login string, id_usuario int) bool {
+	for id, usuario := range s.usuarios {
+		if usuario.Login == login && id != id_usuario {
+			return true
+		}
+	}
+	return false
+}
+
+// existe diz se há um usuário com o id, em qualquer estado
+func (s *UsuarioMemoryStore) existe(id_usuario int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.usuarios[id_usuario]
+	return ok
+}
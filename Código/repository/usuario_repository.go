@@ -6,18 +6,37 @@ import (
 	"go-api/model"
 )
 
+// A coluna ativo guarda o status da conta em um caractere. 'A' e 'I' são os valores
+// anteriores aos estados pendente e suspenso e continuam significando ativo e desativado.
+var statusCodigos = map[string]string{
+	model.StatusPendente:   "P",
+	model.StatusAtivo:      "A",
+	model.StatusSuspenso:   "S",
+	model.StatusDesativado: "I",
+}
+
+func statusDoCodigo(codigo string) string {
+	for status, c := range statusCodigos {
+		if c == codigo {
+			return status
+		}
+	}
+	return model.StatusDesativado
+}
+
 type UsuarioRepository struct {
-	connection *sql.DB
+	connection conexao
 }
 
-func NewUsuarioRepository(connection *sql.DB) UsuarioRepository {
-	return UsuarioRepository{
-		connection: connection,
+func NewUsuarioRepository(connection *sql.DB) *UsuarioRepository {
+	return &UsuarioRepository{
+		connection: novaConexao(connection),
 	}
 }
 
+// GetUsuarios lista os usuários que não foram desativados
 func (ur *UsuarioRepository) GetUsuarios() ([]model.Usuario, error) {
-	query := "SELECT id, nome, login, senha FROM usuario"
+	query := "SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE ativo <> 'I'"
 	rows, err := ur.connection.Query(query)
 	if err != nil {
 		fmt.Println(err)
@@ -26,19 +45,23 @@ func (ur *UsuarioRepository) GetUsuarios() ([]model.Usuario, error) {
 
 	var usuarioList []model.Usuario
 	var usuarioObj model.Usuario
+	var codigo string
 
 	for rows.Next() {
 		err = rows.Scan(
 			&usuarioObj.Id,
 			&usuarioObj.Nome,
 			&usuarioObj.Login,
-			&usuarioObj.Senha)
+			&usuarioObj.Senha,
+			&usuarioObj.Papel,
+			&codigo)
 
 		if err != nil {
 			fmt.Println(err)
 			return []model.Usuario{}, err
 		}
 
+		usuarioObj.Status = statusDoCodigo(codigo)
 		usuarioList = append(usuarioList, usuarioObj)
 	}
 
@@ -47,29 +70,26 @@ func (ur *UsuarioRepository) GetUsuarios() ([]model.Usuario, error) {
 	return usuarioList, nil
 }
 
+// CreateUsuario retorna ErrRegistroDuplicado quando o login já pertence a outro usuário
 func (ur *UsuarioRepository) CreateUsuario(usuario model.Usuario) (int, error) {
-	result, err := ur.connection.Exec(
-		"INSERT INTO usuario (nome, login, senha) VALUES (?, ?, ?)",
+	id, err := ur.connection.insert(
+		"INSERT INTO usuario (nome, login, senha, papel, ativo) VALUES (?, ?, ?, ?, ?)",
 		usuario.Nome,
 		usuario.Login,
 		usuario.Senha,
+		usuario.Papel,
+		statusCodigos[usuario.Status],
 	)
 	if err != nil {
 		fmt.Println(err)
 		return 0, err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		fmt.Println(err)
-		return 0, err
-	}
-
-	return int(id), nil
+	return id, nil
 }
 
 func (ur *UsuarioRepository) GetUsuarioById(id_usuario int) (*model.Usuario, error) {
-	query, err := ur.connection.Prepare("SELECT id, nome, login, senha FROM usuario WHERE id = ?")
+	query, err := ur.connection.Prepare("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")
 	if err != nil {
 		fmt.Println(err)
 		return nil, err
@@ -77,12 +97,15 @@ func (ur *UsuarioRepository) GetUsuarioById(id_usuario int) (*model.Usuario, err
 	defer query.Close()
 
 	var usuario model.Usuario
+	var codigo string
 
 	err = query.QueryRow(id_usuario).Scan(
 		&usuario.Id,
 		&usuario.Nome,
 		&usuario.Login,
 		&usuario.Senha,
+		&usuario.Papel,
+		&codigo,
 	)
 
 	if err != nil {
@@ -91,29 +114,109 @@ func (ur *UsuarioRepository) GetUsuarioById(id_usuario int) (*model.Usuario, err
 		}
 		return nil, err
 	}
+	usuario.Status = statusDoCodigo(codigo)
 
 	return &usuario, nil
 }
 
-func (ur *UsuarioRepository) UpdateUsuarioById(id_usuario int, usuario *model.Usuario) error {
-	query, err := ur.connection.Prepare(`
-		UPDATE usuario 
-		SET nome = ?, login = ?, senha = ?
-		WHERE id = ?
-	`)
+func (ur *UsuarioRepository) UpdateSenhaById(id_usuario int, senha string) error {
+	query, err := ur.connection.Prepare("UPDATE usuario SET senha = ? WHERE id = ?")
 	if err != nil {
 		fmt.Println(err)
 		return err
 	}
 	defer query.Close()
 
-	result, err := query.Exec(
-		usuario.Nome,
-		usuario.Login,
-		usuario.Senha,
-		id_usuario,
-	)
+	result, err := query.Exec(senha, id_usuario)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdatePerfilById retorna sql.ErrNoRows só quando o usuário não existe. Sem nenhuma linha
+// afetada, a existência é conferida à parte: um banco que conte apenas as linhas alteradas
+// responderia zero para quem reenvia o nome e o login atuais.
+func (ur *UsuarioRepository) UpdatePerfilById(id_usuario int, nome, login string) error {
+	query, err := ur.connection.Prepare("UPDATE usuario SET nome = ?, login = ? WHERE id = ?")
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	defer query.Close()
+
+	result, err := query.Exec(nome, login, id_usuario)
+	if err != nil {
+		fmt.Println(err)
+		return erroDoBanco(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		var existe int
+		err = ur.connection.QueryRow("SELECT COUNT(*) FROM usuario WHERE id = ?", id_usuario).Scan(&existe)
+		if err != nil {
+			fmt.Println(err)
+			return err
+		}
+		if existe == 0 {
+			return sql.ErrNoRows
+		}
+	}
+
+	return nil
+}
+
+func (ur *UsuarioRepository) UpdatePapelById(id_usuario int, papel string) error {
+	query, err := ur.connection.Prepare("UPDATE usuario SET papel = ? WHERE id = ?")
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	defer query.Close()
+
+	result, err := query.Exec(papel, id_usuario)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (ur *UsuarioRepository) UpdateStatusById(id_usuario int, status string) error {
+	query, err := ur.connection.Prepare("UPDATE usuario SET ativo = ? WHERE id = ?")
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	defer query.Close()
+
+	result, err := query.Exec(statusCodigos[status], id_usuario)
 	if err != nil {
 		fmt.Println(err)
 		return err
@@ -125,15 +228,15 @@ func (ur *UsuarioRepository) UpdateUsuarioById(id_usuario int, usuario *model.Us
 	}
 
 	if rowsAffected == 0 {
-		// Nenhum registro foi atualizado, talvez o ID não exista
 		return sql.ErrNoRows
 	}
 
 	return nil
 }
 
+// SoftDeleteUsuarioById desativa o usuário a partir de qualquer outro estado
 func (ur *UsuarioRepository) SoftDeleteUsuarioById(id_usuario int) error {
-	query, err := ur.connection.Prepare("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo = 'A'")
+	query, err := ur.connection.Prepare("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo <> 'I'")
 	if err != nil {
 		fmt.Println(err)
 		return err
@@ -157,17 +260,41 @@ func (ur *UsuarioRepository) SoftDeleteUsuarioById(id_usuario int) error {
 	return nil
 }
 
+// DeleteUsuarioById remove o usuário de vez, liberando o login. Serve para desfazer um
+// cadastro que não pôde ser concluído; usuários em uso são desativados com SoftDeleteUsuarioById.
+func (ur *UsuarioRepository) DeleteUsuarioById(id_usuario int) error {
+	result, err := ur.connection.Exec("DELETE FROM usuario WHERE id = ?", id_usuario)
+	if err != nil {
+		fmt.Println(err)
+		return erroDoBanco(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetUsuarioByLogin ignora usuários desativados, que deixam de existir para o login
 func (ur *UsuarioRepository) GetUsuarioByLogin(login string) (*model.Usuario, error) {
-	query := "SELECT id, nome, login, senha FROM usuario WHERE login = ?"
+	query := "SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ? AND ativo <> 'I'"
 	row := ur.connection.QueryRow(query, login)
 
 	var usuario model.Usuario
-	err := row.Scan(&usuario.Id, &usuario.Nome, &usuario.Login, &usuario.Senha)
+	var codigo string
+	err := row.Scan(&usuario.Id, &usuario.Nome, &usuario.Login, &usuario.Senha, &usuario.Papel, &codigo)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
+	usuario.Status = statusDoCodigo(codigo)
 	return &usuario, nil
 }
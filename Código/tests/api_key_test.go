@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var apiKeyColumns = []string{"id", "usuario_id", "nome", "prefixo", "key_hash", "escopos", "expira_em", "ultimo_uso", "revogada", "criada_em"}
+
+func setupApiKeyRouter() (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock := ConnectMockDB()
+	router := gin.Default()
+
+	authUsecase := newAuthUsecase(db)
+	apiKeyController := controller.NewApiKeyController(usecase.NewApiKeyUsecase(repository.NewApiKeyRepository(db), repository.NewUsuarioRepository(db)))
+	router.Use(middleware.Auth(authUsecase))
+
+	router.GET("/tarefas", middleware.RequireScope(model.EscopoTarefasLeitura), func(ctx *gin.Context) {
+		caller, _ := middleware.GetCaller(ctx)
+		ctx.JSON(http.StatusOK, gin.H{"usuario_id": caller.UsuarioId, "papel": caller.Papel})
+	})
+	router.POST("/tarefa", middleware.RequireScope(model.EscopoTarefasEscrita), func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+	router.POST("/api-keys", middleware.RequireSession(), apiKeyController.CreateApiKey)
+	router.DELETE("/api-keys/:apiKeyId", middleware.RequireSession(), apiKeyController.RevokeApiKey)
+
+	return router, mock
+}
+
+func doApiKeyRequest(router *gin.Engine, method, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func expectApiKeyByHash(mock sqlmock.Sqlmock, key, escopos string, expiraEm, ultimoUso interface{}, revogada string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, usuario_id, nome, prefixo, key_hash, escopos, expira_em, ultimo_uso, revogada, criada_em FROM api_key WHERE key_hash = ?")).
+		WithArgs(config.HashOpaqueToken(key)).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(3, 7, "deploy-ci", key[:12], config.HashOpaqueToken(key), escopos, expiraEm, ultimoUso, revogada, time.Now()))
+}
+
+func TestApiKeyManagement(t *testing.T) {
+	token, _ := gerarToken(7, model.PapelMembro, "")
+	bearer := map[string]string{"Authorization": "Bearer " + token}
+
+	t.Run("CriaChaveExibidaUmaVez", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		keyHash := &Captura{}
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO api_key (usuario_id, nome, prefixo, key_hash, escopos, expira_em, revogada, criada_em) VALUES (?, ?, ?, ?, ?, ?, 'N', ?)")).
+			WithArgs(7, "deploy-ci", sqlmock.AnyArg(), keyHash, "tarefas:read,tarefas:write", nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		resp := doApiKeyRequest(router, "POST", "/api-keys", bearer, `{"nome":"deploy-ci","escopos":["tarefas:read","tarefas:write","tarefas:read"]}`)
+		require.Equal(t, http.StatusCreated, resp.Code)
+
+		var created model.ApiKeyCreatedResponse
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+		assert.True(t, strings.HasPrefix(created.Key, config.ApiKeyPrefix))
+		assert.True(t, strings.HasPrefix(created.Key, created.Prefixo))
+		assert.Equal(t, 3, created.Id)
+		assert.Equal(t, config.HashOpaqueToken(created.Key), keyHash.Valor)
+		assert.NotContains(t, resp.Body.String(), keyHash.Valor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("EscopoInvalido", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+
+		resp := doApiKeyRequest(router, "POST", "/api-keys", bearer, `{"nome":"deploy-ci","escopos":["tudo"]}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ExpiracaoNoPassado", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+
+		resp := doApiKeyRequest(router, "POST", "/api-keys", bearer, `{"nome":"deploy-ci","escopos":["tarefas:read"],"expira_em":"2020-01-01T00:00:00Z"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RevogaChaveDeOutroUsuario", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE api_key SET revogada = 'S' WHERE id = ? AND usuario_id = ? AND revogada = 'N'")).
+			WithArgs(9, 7).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		resp := doApiKeyRequest(router, "DELETE", "/api-keys/9", bearer, "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestApiKeyAuthentication(t *testing.T) {
+	key, _, err := config.GenerateApiKey()
+	require.NoError(t, err)
+
+	t.Run("CabecalhoXApiKey", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read", nil, nil, "N")
+		expectUsuarioById(mock, 7, model.PapelAdmin)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE api_key SET ultimo_uso = ? WHERE id = ?")).
+			WithArgs(sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"X-API-Key": key}, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		// O papel vem do usuário atual, não da chave
+		assert.Contains(t, resp.Body.String(), `"papel":"admin"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("BearerComUsoRecente", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read", nil, time.Now().Add(-10*time.Second), "N")
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"Authorization": "Bearer " + key}, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SemEscopo", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read", nil, time.Now(), "N")
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doApiKeyRequest(router, "POST", "/tarefa", map[string]string{"X-API-Key": key}, "")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NaoGerenciaApiKeys", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read,tarefas:write,usuarios:read,usuarios:write", nil, time.Now(), "N")
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doApiKeyRequest(router, "POST", "/api-keys", map[string]string{"X-API-Key": key}, `{"nome":"outra","escopos":["tarefas:read"]}`)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revogada", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read", nil, nil, "S")
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"X-API-Key": key}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Expirada", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		expectApiKeyByHash(mock, key, "tarefas:read", time.Now().Add(-time.Minute), nil, "N")
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"X-API-Key": key}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Desconhecida", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM api_key WHERE key_hash = ?")).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"X-API-Key": "gak_desconhecida"}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
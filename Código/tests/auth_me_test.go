@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"go-api/controller"
+	"go-api/middleware"
+	"go-api/model"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMeRouter() (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock := ConnectMockDB()
+	router := gin.Default()
+
+	authUsecase := newAuthUsecase(db)
+	authController := controller.NewAuthController(authUsecase)
+	router.Use(middleware.Auth(authUsecase))
+
+	router.GET("/auth/me", authController.Me)
+	router.PATCH("/auth/me", authController.UpdateMe)
+	router.POST("/auth/change-password", middleware.RequireSession(), authController.ChangePassword)
+
+	return router, mock
+}
+
+func doMeRequest(router *gin.Engine, method, path, sessionId, body string) *httptest.ResponseRecorder {
+	token, _ := gerarToken(7, model.PapelMembro, sessionId)
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func expectUsuarioComSenha(mock sqlmock.Sqlmock, id int, senha string) {
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+		ExpectQuery().
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(id, "João", "joao", senha, model.PapelMembro, "A"))
+}
+
+func TestMe(t *testing.T) {
+	router, mock := setupMeRouter()
+
+	t.Run("RetornaOUsuarioDoToken", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doMeRequest(router, "GET", "/auth/me", "", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"id_usuario":7,"nome_usuario":"João","login_usuario":"joao","papel_usuario":"membro","status_usuario":"ativo"}`, resp.Body.String())
+	})
+
+	t.Run("SemToken", func(t *testing.T) {
+		resp := doAuthRequest(router, "/auth/me", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("AlteraApenasONome", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+		// O próprio usuário já tem o login mantido
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("joao").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+				AddRow(7, "João", "joao", "hash", model.PapelMembro, "A"))
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET nome = ?, login = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs("João Silva", "joao", 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doMeRequest(router, "PATCH", "/auth/me", "", `{"nome_usuario":"João Silva"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"nome_usuario":"João Silva"`)
+	})
+
+	t.Run("LoginDeOutroUsuario", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+		expectUsuarioByLogin(mock, "maria", "hash")
+
+		resp := doMeRequest(router, "PATCH", "/auth/me", "", `{"login_usuario":"maria"}`)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("NomeVazio", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doMeRequest(router, "PATCH", "/auth/me", "", `{"nome_usuario":"  "}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePassword(t *testing.T) {
+	hash, _ := senhasTeste.Hash("senha123")
+
+	t.Run("RevogaAsOutrasSessoes", func(t *testing.T) {
+		router, mock := setupMeRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-atual", 7, time.Now(), nil)
+		expectUsuarioComSenha(mock, 7, hash)
+		expectSemTentativas(mock, "login:joao", "ip:"+ipTeste)
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET senha = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs(BcryptOf("novaSenha456"), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectTentativasZeradas(mock, "joao")
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ? AND familia <> ?")).
+			WithArgs(7, "sessao-atual").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND id <> ? AND encerrada_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 7, "sessao-atual").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		resp := doMeRequest(router, "POST", "/auth/change-password", "sessao-atual", `{"senha_atual":"senha123","nova_senha":"novaSenha456"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SenhaAtualIncorreta", func(t *testing.T) {
+		router, mock := setupMeRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-atual", 7, time.Now(), nil)
+		expectUsuarioComSenha(mock, 7, hash)
+		expectSemTentativas(mock, "login:joao", "ip:"+ipTeste)
+		expectFalhaRegistrada(mock, "login:joao", 1)
+		expectFalhaRegistrada(mock, "ip:"+ipTeste, 1)
+
+		resp := doMeRequest(router, "POST", "/auth/change-password", "sessao-atual", `{"senha_atual":"errada","nova_senha":"novaSenha456"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SemSenhaAtual", func(t *testing.T) {
+		router, mock := setupMeRouter()
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-atual", 7, time.Now(), nil)
+
+		resp := doMeRequest(router, "POST", "/auth/change-password", "sessao-atual", `{"nova_senha":"novaSenha456"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
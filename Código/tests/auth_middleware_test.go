@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/middleware"
+	"go-api/model"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAuthRouter() (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock := ConnectMockDB()
+	router := gin.Default()
+
+	authUsecase := newAuthUsecase(db)
+	authController := controller.NewAuthController(authUsecase)
+	router.Use(middleware.Auth(authUsecase, "/ping", "/.well-known/jwks.json"))
+
+	router.GET("/ping", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"message": "pong"})
+	})
+	router.GET("/protegida", func(ctx *gin.Context) {
+		userId, _ := middleware.GetUserId(ctx)
+		ctx.JSON(http.StatusOK, gin.H{"user_id": userId})
+	})
+	router.POST("/auth/logout", authController.Logout)
+	router.GET("/.well-known/jwks.json", authController.JWKS)
+
+	return router, mock
+}
+
+func expectTokenNotRevoked(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM token_revogado WHERE jti = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+// expectTokenValido responde às consultas de toda requisição com token de acesso: a revogação
+// do token e a situação do usuário
+func expectTokenValido(mock sqlmock.Sqlmock, id int, papel string) {
+	expectTokenNotRevoked(mock)
+	expectUsuarioById(mock, id, papel)
+}
+
+func doAuthRequest(router *gin.Engine, path, authorization string) *httptest.ResponseRecorder {
+	return doAuthRequestWithMethod(router, "GET", path, authorization)
+}
+
+func doAuthRequestWithMethod(router *gin.Engine, method, path, authorization string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	router, mock := setupAuthRouter()
+
+	t.Run("RotaPublica", func(t *testing.T) {
+		resp := doAuthRequest(router, "/ping", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("SemToken", func(t *testing.T) {
+		resp := doAuthRequest(router, "/protegida", "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("TokenValido", func(t *testing.T) {
+		token, err := gerarToken(7, "membro", "")
+		assert.NoError(t, err)
+		expectTokenValido(mock, 7, model.PapelMembro)
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var body map[string]int
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		assert.Equal(t, 7, body["user_id"])
+	})
+
+	t.Run("TokenAdulterado", func(t *testing.T) {
+		token, _ := gerarToken(7, "membro", "")
+		tampered := token[:len(token)-2] + "xx"
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+tampered)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("TokenDeOutraChave", func(t *testing.T) {
+		claims := config.Claims{
+			UserId: 7,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("outra-chave"))
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("AlgoritmoNone", func(t *testing.T) {
+		claims := config.Claims{UserId: 7}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("TokenRevogadoPorOutraInstancia", func(t *testing.T) {
+		token, _ := gerarToken(7, "membro", "")
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM token_revogado WHERE jti = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("UsuarioSuspenso", func(t *testing.T) {
+		token, _ := gerarToken(7, "membro", "")
+		expectTokenNotRevoked(mock)
+		mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+			ExpectQuery().
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+				AddRow(7, "João", "joao", "hash", "membro", "S"))
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("UsuarioExcluido", func(t *testing.T) {
+		token, _ := gerarToken(7, "membro", "")
+		expectTokenNotRevoked(mock)
+		mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+			ExpectQuery().
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+
+		resp := doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		token, _ := gerarToken(7, "membro", "sessao-1")
+		claims, _ := chavesTeste.ParseToken(token)
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-1", 7, time.Now(), nil)
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO token_revogado (jti, expira_em) VALUES (?, ?)")).
+			WithArgs(claims.ID, claims.ExpiresAt.Time).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFamiliaRevogada(mock, "sessao-1")
+
+		resp := doAuthRequestWithMethod(router, "POST", "/auth/logout", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		// O token revogado é recusado pelo cache, sem nova consulta ao banco
+		resp = doAuthRequest(router, "/protegida", "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteExpiredTokens(t *testing.T) {
+	db, mock := ConnectMockDB()
+	tokenUsecase := newTokenUsecase(db)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM token_revogado WHERE expira_em <= ?")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM refresh_token WHERE expira_em <= ?")).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM sessao WHERE encerrada_em IS NOT NULL OR (criada_em < ? AND NOT EXISTS")).
+		WithArgs(TempoProximo(-time.Hour), TempoProximo(-time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, tokenUsecase.DeleteExpiredTokens())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
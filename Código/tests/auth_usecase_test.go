@@ -0,0 +1,192 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"go-api/config"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTokenUsecase(db *sql.DB) *usecase.TokenUsecase {
+	return usecase.NewTokenUseCase(repository.NewTokenRepository(db), repository.NewRefreshTokenRepository(db), repository.NewSessaoRepository(db), cfgTeste.JWT, chavesTeste)
+}
+
+func newAuthUsecase(db *sql.DB) *usecase.AuthUsecase {
+	tentativas := usecase.NewTentativaLoginUsecase(repository.NewTentativaLoginRepository(db), loginTeste)
+	doisFatores := usecase.NewDoisFatoresUsecase(repository.NewDoisFatoresRepository(db), repository.NewUsuarioRepository(db))
+	apiKeys := usecase.NewApiKeyUsecase(repository.NewApiKeyRepository(db), repository.NewUsuarioRepository(db))
+	return usecase.NewAuthUsecase(repository.NewUsuarioRepository(db), newTokenUsecase(db), tentativas, doisFatores, apiKeys, senhasTeste)
+}
+
+var tentativaLoginColumns = []string{"chave", "falhas", "bloqueado_ate", "atualizado_em"}
+
+func expectTentativas(mock sqlmock.Sqlmock, rows *sqlmock.Rows, chaves ...string) {
+	args := make([]driver.Value, len(chaves))
+	for i, chave := range chaves {
+		args[i] = chave
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chave, falhas, bloqueado_ate, atualizado_em FROM tentativa_login WHERE chave IN")).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+func expectSemTentativas(mock sqlmock.Sqlmock, chaves ...string) {
+	expectTentativas(mock, sqlmock.NewRows(tentativaLoginColumns), chaves...)
+}
+
+func expectTentativasZeradas(mock sqlmock.Sqlmock, login string) {
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM tentativa_login WHERE chave = ?")).
+		WithArgs("login:" + login).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectFalhaRegistrada espera o incremento da chave de tentativa, com o banco devolvendo o
+// número de falhas informado
+func expectFalhaRegistrada(mock sqlmock.Sqlmock, chave string, falhas int) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO tentativa_login (chave, falhas, bloqueado_ate, atualizado_em) VALUES (?, 1, ?, ?) ON CONFLICT (chave) DO UPDATE")).
+		WithArgs(chave, sqlmock.AnyArg(), sqlmock.AnyArg(), TempoProximo(-config.LoginJanelaFalhas)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT falhas FROM tentativa_login WHERE chave = ?")).
+		WithArgs(chave).
+		WillReturnRows(sqlmock.NewRows([]string{"falhas"}).AddRow(falhas))
+	mock.ExpectCommit()
+}
+
+func expectRefreshTokenCreated(mock sqlmock.Sqlmock, usuarioId int) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO refresh_token")).
+		WithArgs(sqlmock.AnyArg(), usuarioId, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func expectSemDoisFatores(mock sqlmock.Sqlmock, usuarioId int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT usuario_id, segredo, confirmado, ultimo_passo FROM usuario_2fa WHERE usuario_id = ?")).
+		WithArgs(usuarioId).
+		WillReturnRows(sqlmock.NewRows([]string{"usuario_id", "segredo", "confirmado", "ultimo_passo"}))
+}
+
+// expectSessaoIniciada espera o registro de uma nova sessão e a emissão do primeiro par de tokens dela
+func expectSessaoIniciada(mock sqlmock.Sqlmock, usuarioId int) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO sessao (id, usuario_id, dispositivo, ip, criada_em, ultimo_uso) VALUES (?, ?, ?, ?, ?, ?)")).
+		WithArgs(sqlmock.AnyArg(), usuarioId, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRefreshTokenCreated(mock, usuarioId)
+}
+
+// expectLoginConcluido espera as etapas após a senha correta de um usuário sem segundo fator
+func expectLoginConcluido(mock sqlmock.Sqlmock, login string, usuarioId int) {
+	expectSemDoisFatores(mock, usuarioId)
+	expectTentativasZeradas(mock, login)
+	expectSessaoIniciada(mock, usuarioId)
+}
+
+func expectUsuarioByLogin(mock sqlmock.Sqlmock, login, senha string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+		WithArgs(login).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(1, "João", login, senha, "membro", "A"))
+}
+
+func TestLogin(t *testing.T) {
+	t.Run("SenhaComHash", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		hash, _ := senhasTeste.Hash("senha123")
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", hash)
+		expectLoginConcluido(mock, "joao", 1)
+
+		tokens, err := authUsecase.Login("joao", "senha123", model.Origem{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.Token)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SenhaIncorreta", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		hash, _ := senhasTeste.Hash("senha123")
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", hash)
+		expectFalhaRegistrada(mock, "login:joao", 1)
+
+		_, err := authUsecase.Login("joao", "errada", model.Origem{})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LoginInexistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectSemTentativas(mock, "login:ninguem")
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("ninguem").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+		expectFalhaRegistrada(mock, "login:ninguem", 1)
+
+		_, err := authUsecase.Login("ninguem", "senha123", model.Origem{})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MigraSenhaEmTextoPuro", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", "senha123")
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET senha = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs(BcryptOf("senha123"), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectLoginConcluido(mock, "joao", 1)
+
+		tokens, err := authUsecase.Login("joao", "senha123", model.Origem{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.Token)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TextoPuroIncorreto", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", "senha123")
+		expectFalhaRegistrada(mock, "login:joao", 1)
+
+		_, err := authUsecase.Login("joao", "senha12", model.Origem{})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RefazHashComCustoDesatualizado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		oldHash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), senhasTeste.Cost+1)
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", string(oldHash))
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET senha = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs(BcryptOf("senha123"), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectLoginConcluido(mock, "joao", 1)
+
+		_, err := authUsecase.Login("joao", "senha123", model.Origem{})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
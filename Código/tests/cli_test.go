@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go-api/app"
+	"go-api/cli"
+	"go-api/config"
+	"go-api/model"
+	"go-api/usecase"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cliConfig aponta para um banco SQLite em arquivo, exclusivo do teste e ainda sem migrations
+func cliConfig(t *testing.T) *config.Config {
+	cfg := config.Default()
+	cfg.Database.Driver = config.DriverSQLite
+	cfg.Database.Path = filepath.Join(t.TempDir(), "api.db")
+	cfg.Database.AutoMigrate = false
+	cfg.Password.Cost = senhasTeste.Cost
+	return cfg
+}
+
+// executaCli roda o comando com a entrada padrão stdin e retorna o que ele imprimiu
+func executaCli(t *testing.T, cfg *config.Config, stdin string, args ...string) (string, error) {
+	var saida bytes.Buffer
+	stdinAnterior, stdoutAnterior, stderrAnterior := cli.Stdin, cli.Stdout, cli.Stderr
+	cli.Stdin, cli.Stdout, cli.Stderr = strings.NewReader(stdin), &saida, &saida
+	t.Cleanup(func() { cli.Stdin, cli.Stdout, cli.Stderr = stdinAnterior, stdoutAnterior, stderrAnterior })
+
+	serve := func(ctx context.Context, a *app.App) error {
+		t.Fatal("o servidor não deveria ser iniciado")
+		return nil
+	}
+	err := cli.Run(context.Background(), cfg, args, serve)
+	return saida.String(), err
+}
+
+func TestCliMigrate(t *testing.T) {
+	cfg := cliConfig(t)
+
+	saida, err := executaCli(t, cfg, "", "migrate", "status")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "0001    usuario_tarefa  pendente")
+
+	saida, err = executaCli(t, cfg, "", "migrate", "up")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "Migration 0001_usuario_tarefa aplicada")
+	assert.Contains(t, saida, "Migration 0002_autenticacao aplicada")
+
+	saida, err = executaCli(t, cfg, "", "migrate", "up")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "O esquema já está na versão 0002")
+
+	saida, err = executaCli(t, cfg, "", "migrate", "down", "-steps", "2")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "Migration 0002_autenticacao revertida")
+	assert.Contains(t, saida, "Migration 0001_usuario_tarefa revertida")
+
+	saida, err = executaCli(t, cfg, "", "migrate", "status")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "0002    autenticacao    pendente")
+}
+
+func TestCliUsuario(t *testing.T) {
+	cfg := cliConfig(t)
+	// Os comandos de usuário aplicam as migrations como o servidor
+	cfg.Database.AutoMigrate = true
+
+	// A senha pode vir da entrada padrão para não aparecer na lista de processos
+	saida, err := executaCli(t, cfg, "senha123\n", "user", "create", "-login", "admin", "-name", "Administrador", "-admin")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "Usuário 1 criado: admin (admin)")
+
+	_, err = executaCli(t, cfg, "", "user", "create", "-login", "admin", "-name", "Outro", "-password", "senha123")
+	assert.ErrorIs(t, err, usecase.ErrLoginEmUso)
+	_, err = executaCli(t, cfg, "", "user", "create", "-login", "sem-nome", "-password", "senha123")
+	assert.EqualError(t, err, "informe -name")
+	_, err = executaCli(t, cfg, "", "user", "create", "-login", "sem-senha", "-name", "Sem senha")
+	assert.Error(t, err)
+
+	_, err = executaCli(t, cfg, "", "user", "reset-password", "-login", "admin", "-password", "nova-senha")
+	require.NoError(t, err)
+	_, err = executaCli(t, cfg, "", "user", "reset-password", "-login", "ninguem", "-password", "nova-senha")
+	assert.ErrorIs(t, err, usecase.ErrUsuarioInexistente)
+
+	conn, err := sql.Open("sqlite3", cfg.Database.Path)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	var papel, hash string
+	require.NoError(t, conn.QueryRow("SELECT papel, senha FROM usuario WHERE login = 'admin'").Scan(&papel, &hash))
+	assert.Equal(t, model.PapelAdmin, papel)
+	ok, _ := senhasTeste.Check(hash, "nova-senha")
+	assert.True(t, ok)
+
+	// Sem chave configurada o token seria assinado com uma chave temporária
+	_, err = executaCli(t, cfg, "", "token", "issue", "-login", "admin")
+	assert.Error(t, err)
+	cfg.JWT.Secret = "segredo-de-teste-com-32-caracteres"
+	saida, err = executaCli(t, cfg, "", "token", "issue", "-login", "admin")
+	require.NoError(t, err)
+	var tokens model.TokenResponse
+	require.NoError(t, json.Unmarshal([]byte(saida[strings.Index(saida, "{"):]), &tokens))
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	var sessoes int
+	require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM sessao WHERE dispositivo = 'go-api token issue'").Scan(&sessoes))
+	assert.Equal(t, 1, sessoes)
+}
+
+func TestCliSeed(t *testing.T) {
+	cfg := cliConfig(t)
+	cfg.Database.AutoMigrate = true
+
+	saida, err := executaCli(t, cfg, "", "seed")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "Usuário 1 criado: admin (admin)")
+
+	// Rodar de novo não duplica nada
+	saida, err = executaCli(t, cfg, "", "seed")
+	require.NoError(t, err)
+	assert.Contains(t, saida, "Usuário admin já existe")
+
+	conn, err := sql.Open("sqlite3", cfg.Database.Path)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	var usuarios, tarefas int
+	require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM usuario").Scan(&usuarios))
+	require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM tarefa").Scan(&tarefas))
+	assert.Equal(t, 3, usuarios)
+	assert.Equal(t, 3, tarefas)
+}
+
+func TestCliComandoInvalido(t *testing.T) {
+	cfg := cliConfig(t)
+
+	saida, err := executaCli(t, cfg, "", "migrate", "sideways")
+	assert.ErrorIs(t, err, cli.ErrComandoDesconhecido)
+	assert.Contains(t, saida, "user reset-password")
+
+	_, err = executaCli(t, cfg, "", "migrate", "down", "extra")
+	assert.Error(t, err)
+
+	// Os dados de um banco em memória se perderiam ao terminar o comando
+	cfg.Database.Driver = config.DriverMemory
+	_, err = executaCli(t, cfg, "", "seed")
+	assert.Error(t, err)
+}
+
+func TestConfigLoadArgs(t *testing.T) {
+	_, args, err := config.LoadArgs([]string{"-database-port", "3309", "migrate", "down", "-steps", "2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "down", "-steps", "2"}, args)
+}
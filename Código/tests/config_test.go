@@ -0,0 +1,147 @@
+package main
+
+import (
+	"go-api/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func escreveArquivo(t *testing.T, nome, conteudo string) string {
+	path := filepath.Join(t.TempDir(), nome)
+	require.NoError(t, os.WriteFile(path, []byte(conteudo), 0600))
+	return path
+}
+
+func TestConfigLoad(t *testing.T) {
+	t.Run("ValoresPadrao", func(t *testing.T) {
+		cfg, err := config.Load(nil)
+		require.NoError(t, err)
+		assert.Equal(t, ":8000", cfg.Server.Addr)
+		assert.Equal(t, 3306, cfg.Database.Port)
+		assert.Empty(t, cfg.Database.Password)
+		assert.Equal(t, 15*time.Minute, cfg.JWT.AccessTokenDuration.Duration)
+		assert.Equal(t, 5, cfg.LoginProtection.MaxFalhas)
+	})
+
+	t.Run("ArquivoAmbienteEFlags", func(t *testing.T) {
+		path := escreveArquivo(t, "api.yaml", `
+server:
+  addr: ":9000"
+database:
+  host: db.arquivo
+  port: 3307
+login:
+  bloqueio_duracao: 30m
+`)
+		t.Setenv("DB_HOST", "db.ambiente")
+		t.Setenv("DB_PORT", "3308")
+
+		cfg, err := config.Load([]string{"-config", path, "-database-port", "3309"})
+		require.NoError(t, err)
+		// O arquivo sobrescreve o padrão, o ambiente sobrescreve o arquivo e as flags sobrescrevem o ambiente
+		assert.Equal(t, ":9000", cfg.Server.Addr)
+		assert.Equal(t, "db.ambiente", cfg.Database.Host)
+		assert.Equal(t, 3309, cfg.Database.Port)
+		assert.Equal(t, "trabgb", cfg.Database.Name)
+		assert.Equal(t, 30*time.Minute, cfg.LoginProtection.BloqueioDuracao.Duration)
+	})
+
+	t.Run("ArquivoToml", func(t *testing.T) {
+		path := escreveArquivo(t, "api.toml", `
+[database]
+name = "outro"
+
+[oidc]
+issuer = "https://idp.exemplo.com"
+client_id = "go-api"
+redirect_url = "http://localhost:8000/auth/oidc/callback"
+`)
+		t.Setenv("CONFIG_FILE", path)
+
+		cfg, err := config.Load(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "outro", cfg.Database.Name)
+		assert.Equal(t, "go-api", cfg.OIDC.ClientId)
+	})
+
+	t.Run("SegredoEmArquivo", func(t *testing.T) {
+		t.Setenv("DB_PASSWORD_FILE", escreveArquivo(t, "db_password", "s3nh4-do-banco\n"))
+
+		cfg, err := config.Load(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "s3nh4-do-banco", cfg.Database.Password)
+	})
+
+	t.Run("AmbienteSubstituiSegredoEmArquivo", func(t *testing.T) {
+		path := escreveArquivo(t, "api.yml", "database:\n  password_file: /nao/existe\n")
+		t.Setenv("DB_PASSWORD", "do-ambiente")
+
+		cfg, err := config.Load([]string{"-config", path})
+		require.NoError(t, err)
+		assert.Equal(t, "do-ambiente", cfg.Database.Password)
+	})
+
+	t.Run("ReportaTodosOsErros", func(t *testing.T) {
+		t.Setenv("LOGIN_MAX_FALHAS", "0")
+		t.Setenv("JWT_SECRET", "curto")
+		t.Setenv("OIDC_ISSUER", "https://idp.exemplo.com")
+
+		_, err := config.Load(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "login.max_falhas")
+		assert.Contains(t, err.Error(), "jwt.secret: deve ter pelo menos 32 bytes")
+		assert.Contains(t, err.Error(), "oidc.client_id")
+		assert.Contains(t, err.Error(), "oidc.redirect_url")
+	})
+
+	t.Run("ValorInvalidoNoAmbiente", func(t *testing.T) {
+		t.Setenv("DB_PORT", "mysql")
+
+		_, err := config.Load(nil)
+		assert.ErrorContains(t, err, "DB_PORT")
+	})
+
+	t.Run("ChaveDesconhecidaNoArquivo", func(t *testing.T) {
+		path := escreveArquivo(t, "api.yaml", "database:\n  hots: db\n")
+
+		_, err := config.Load([]string{"-config", path})
+		assert.ErrorContains(t, err, "hots")
+	})
+
+	t.Run("ArquivoDeSegredoInexistente", func(t *testing.T) {
+		_, err := config.Load([]string{"-jwt-secret-file", filepath.Join(t.TempDir(), "nao-existe")})
+		assert.ErrorContains(t, err, "jwt.secret_file")
+	})
+}
+
+func TestConfigLoginProtectionEPassword(t *testing.T) {
+	t.Setenv("LOGIN_MAX_FALHAS", "8")
+	t.Setenv("PASSWORD_COST", "10")
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.LoginProtection.MaxFalhas)
+	assert.Equal(t, 10, cfg.Password.Cost)
+
+	t.Setenv("PASSWORD_COST", "40")
+	_, err = config.Load(nil)
+	assert.Error(t, err)
+}
+
+func TestConfigTrustedProxies(t *testing.T) {
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Server.TrustedProxies)
+
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8, 127.0.0.1")
+	cfg, err = config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "127.0.0.1"}, cfg.Server.TrustedProxies)
+
+	_, err = config.Load([]string{"-server-trusted-proxies", "proxy.interno"})
+	assert.ErrorContains(t, err, "server.trusted_proxies")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"go-api/config"
+	"go-api/db"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// retryRapido encurta as esperas entre tentativas durante o teste
+func retryRapido(t *testing.T) {
+	inicial, maximo := db.RetryInicial, db.RetryMaximo
+	db.RetryInicial, db.RetryMaximo = time.Millisecond, 4*time.Millisecond
+	t.Cleanup(func() { db.RetryInicial, db.RetryMaximo = inicial, maximo })
+}
+
+func TestConnect(t *testing.T) {
+	t.Run("TentaNovamenteAteOBancoResponder", func(t *testing.T) {
+		retryRapido(t)
+		conn, mock, err := sqlmock.NewWithDSN("connect-retry", sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		mock.ExpectPing()
+
+		cfg := config.Default().Database
+		pool, err := db.Connect(context.Background(), "sqlmock", "connect-retry", cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { pool.Close() })
+		assert.Equal(t, cfg.MaxOpenConns, pool.Stats().MaxOpenConnections)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DesisteAoFimDoPrazo", func(t *testing.T) {
+		retryRapido(t)
+		conn, mock, err := sqlmock.NewWithDSN("connect-prazo", sqlmock.MonitorPingsOption(true))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for i := 0; i < 100; i++ {
+			mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+		}
+
+		cfg := config.Default().Database
+		cfg.ConnectTimeout = config.Duration{Duration: 20 * time.Millisecond}
+		inicio := time.Now()
+		_, err = db.Connect(context.Background(), "sqlmock", "connect-prazo", cfg)
+		assert.ErrorContains(t, err, "connection refused")
+		assert.Less(t, time.Since(inicio), time.Second)
+	})
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"go-api/config"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Segredo "12345678901234567890" dos vetores de teste da RFC 6238
+const segredoRFC = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+var doisFatoresColumns = []string{"usuario_id", "segredo", "confirmado", "ultimo_passo"}
+
+func expectDoisFatores(mock sqlmock.Sqlmock, usuarioId int, segredo, confirmado string, ultimoPasso int64) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT usuario_id, segredo, confirmado, ultimo_passo FROM usuario_2fa WHERE usuario_id = ?")).
+		WithArgs(usuarioId).
+		WillReturnRows(sqlmock.NewRows(doisFatoresColumns).AddRow(usuarioId, segredo, confirmado, ultimoPasso))
+}
+
+func codigoAtual(t *testing.T) (string, int64) {
+	passo := config.TOTPPasso(time.Now())
+	codigo, err := config.TOTPCode(segredoRFC, passo)
+	require.NoError(t, err)
+	return codigo, passo
+}
+
+func TestTOTP(t *testing.T) {
+	t.Run("VetoresRFC6238", func(t *testing.T) {
+		codigo, err := config.TOTPCode(segredoRFC, config.TOTPPasso(time.Unix(59, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, "287082", codigo)
+
+		codigo, err = config.TOTPCode(segredoRFC, config.TOTPPasso(time.Unix(1111111109, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, "081804", codigo)
+	})
+
+	t.Run("JanelaDeTolerancia", func(t *testing.T) {
+		agora := time.Unix(1111111109, 0)
+		anterior, _ := config.TOTPCode(segredoRFC, config.TOTPPasso(agora)-1)
+		antigo, _ := config.TOTPCode(segredoRFC, config.TOTPPasso(agora)-2)
+
+		passo, ok := config.ValidateTOTP(segredoRFC, anterior, agora)
+		assert.True(t, ok)
+		assert.Equal(t, config.TOTPPasso(agora)-1, passo)
+
+		_, ok = config.ValidateTOTP(segredoRFC, antigo, agora)
+		assert.False(t, ok)
+	})
+
+	t.Run("URI", func(t *testing.T) {
+		uri := config.TOTPURI("joao", segredoRFC)
+		assert.True(t, strings.HasPrefix(uri, "otpauth://totp/go-api:joao?"))
+		assert.Contains(t, uri, "secret="+segredoRFC)
+		assert.Contains(t, uri, "issuer=go-api")
+	})
+
+	t.Run("TokenDeDesafioNaoEhTokenDeAcesso", func(t *testing.T) {
+		challenge, err := chavesTeste.GenerateChallengeToken(1)
+		require.NoError(t, err)
+		_, err = chavesTeste.ParseToken(challenge)
+		assert.ErrorIs(t, err, config.ErrTokenInvalido)
+
+		access, err := gerarToken(1, "admin", "")
+		require.NoError(t, err)
+		_, err = chavesTeste.ParseChallengeToken(access)
+		assert.ErrorIs(t, err, config.ErrTokenInvalido)
+	})
+}
+
+func TestDoisFatoresEnrollment(t *testing.T) {
+	db, mock := ConnectMockDB()
+	doisFatores := usecase.NewDoisFatoresUsecase(repository.NewDoisFatoresRepository(db), repository.NewUsuarioRepository(db))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT usuario_id, segredo, confirmado, ultimo_passo FROM usuario_2fa WHERE usuario_id = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(doisFatoresColumns))
+	expectUsuarioById(mock, 1, "admin")
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE usuario_2fa SET segredo = ?, confirmado = 'N', ultimo_passo = 0 WHERE usuario_id = ?")).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario_2fa")).
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	enroll, err := doisFatores.Enroll(1)
+	require.NoError(t, err)
+	assert.Len(t, enroll.Segredo, 32)
+	assert.Contains(t, enroll.OtpauthURI, "secret="+enroll.Segredo)
+
+	t.Run("CodigoErradoNaoConfirma", func(t *testing.T) {
+		expectDoisFatores(mock, 1, segredoRFC, "N", 0)
+
+		_, err := doisFatores.Confirm(1, "000000")
+		assert.ErrorIs(t, err, usecase.ErrCodigoDoisFatores)
+	})
+
+	t.Run("ConfirmaEGeraCodigosDeRecuperacao", func(t *testing.T) {
+		codigo, passo := codigoAtual(t)
+		expectDoisFatores(mock, 1, segredoRFC, "N", 0)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usuario_2fa SET ultimo_passo = ?, confirmado = 'S' WHERE usuario_id = ? AND ultimo_passo < ?")).
+			WithArgs(passo, 1, passo).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM codigo_recuperacao WHERE usuario_id = ?")).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		for i := 0; i < 10; i++ {
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO codigo_recuperacao (usuario_id, codigo_hash) VALUES (?, ?)")).
+				WithArgs(1, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+		}
+		mock.ExpectCommit()
+
+		codigos, err := doisFatores.Confirm(1, codigo)
+		require.NoError(t, err)
+		assert.Len(t, codigos, 10)
+		assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, codigos[0])
+	})
+
+	t.Run("JaAtivo", func(t *testing.T) {
+		expectDoisFatores(mock, 1, segredoRFC, "S", 0)
+
+		_, err := doisFatores.Enroll(1)
+		assert.ErrorIs(t, err, usecase.ErrDoisFatoresJaAtivo)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginDoisFatores(t *testing.T) {
+	hash, _ := senhasTeste.Hash("senha123")
+
+	t.Run("LoginRetornaDesafio", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectSemTentativas(mock, "login:joao")
+		expectUsuarioByLogin(mock, "joao", hash)
+		expectDoisFatores(mock, 1, segredoRFC, "S", 0)
+
+		response, err := authUsecase.Login("joao", "senha123", model.Origem{})
+		require.NoError(t, err)
+		assert.True(t, response.DoisFatores)
+		assert.Empty(t, response.Token)
+		assert.Empty(t, response.RefreshToken)
+
+		claims, err := chavesTeste.ParseChallengeToken(response.ChallengeToken)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claims.UserId)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CodigoValidoEmiteTokens", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+		challenge, _ := chavesTeste.GenerateChallengeToken(1)
+		codigo, passo := codigoAtual(t)
+
+		expectTokenNotRevoked(mock)
+		expectUsuarioById(mock, 1, "admin")
+		expectSemTentativas(mock, "login:joao")
+		expectDoisFatores(mock, 1, segredoRFC, "S", passo-5)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE usuario_2fa SET ultimo_passo = ?")).
+			WithArgs(passo, 1, passo).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO token_revogado (jti, expira_em) VALUES (?, ?)")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectTentativasZeradas(mock, "joao")
+		expectSessaoIniciada(mock, 1)
+
+		tokens, err := authUsecase.VerifyDoisFatores(challenge, codigo, model.Origem{})
+		require.NoError(t, err)
+		claims, err := chavesTeste.ParseToken(tokens.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", claims.Papel)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CodigoReutilizadoEhRecusado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+		challenge, _ := chavesTeste.GenerateChallengeToken(1)
+		codigo, passo := codigoAtual(t)
+
+		expectTokenNotRevoked(mock)
+		expectUsuarioById(mock, 1, "admin")
+		expectSemTentativas(mock, "login:joao")
+		expectDoisFatores(mock, 1, segredoRFC, "S", passo)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE codigo_recuperacao SET usado_em = ?")).
+			WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		expectFalhaRegistrada(mock, "login:joao", 1)
+
+		_, err := authUsecase.VerifyDoisFatores(challenge, codigo, model.Origem{})
+		assert.ErrorIs(t, err, usecase.ErrCodigoDoisFatores)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CodigoDeRecuperacao", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+		challenge, _ := chavesTeste.GenerateChallengeToken(1)
+
+		expectTokenNotRevoked(mock)
+		expectUsuarioById(mock, 1, "admin")
+		expectSemTentativas(mock, "login:joao")
+		expectDoisFatores(mock, 1, segredoRFC, "S", 0)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE codigo_recuperacao SET usado_em = ? WHERE usuario_id = ? AND codigo_hash = ? AND usado_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 1, config.HashOpaqueToken("abcdefghij")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO token_revogado")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectTentativasZeradas(mock, "joao")
+		expectSessaoIniciada(mock, 1)
+
+		_, err := authUsecase.VerifyDoisFatores(challenge, "ABCDE-FGHIJ", model.Origem{})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenDeAcessoNaoServeDeDesafio", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+		access, _ := gerarToken(1, "admin", "")
+
+		_, err := authUsecase.VerifyDoisFatores(access, "123456", model.Origem{})
+		assert.ErrorIs(t, err, usecase.ErrChallengeTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"go-api/config"
+	"go-api/httpserver"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpServer(t *testing.T) {
+	t.Run("AplicaOsLimites", func(t *testing.T) {
+		cfg := config.Default().Server
+		server := httpserver.New(cfg, http.NotFoundHandler())
+
+		assert.Equal(t, cfg.ReadHeaderTimeout.Duration, server.ReadHeaderTimeout)
+		assert.Equal(t, cfg.WriteTimeout.Duration, server.WriteTimeout)
+		assert.Equal(t, cfg.IdleTimeout.Duration, server.IdleTimeout)
+		assert.Equal(t, cfg.MaxHeaderBytes, server.MaxHeaderBytes)
+	})
+
+	t.Run("ConcluiRequisicoesEmAndamento", func(t *testing.T) {
+		iniciada := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(iniciada)
+			time.Sleep(100 * time.Millisecond)
+			io.WriteString(w, "concluída")
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		cfg := config.Default().Server
+		ctx, cancel := context.WithCancel(context.Background())
+
+		encerrado := make(chan error, 1)
+		go func() {
+			encerrado <- httpserver.Serve(ctx, httpserver.New(cfg, handler), listener, cfg)
+		}()
+
+		resposta := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + listener.Addr().String())
+			if err != nil {
+				resposta <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			resposta <- string(body)
+		}()
+
+		// O sinal de encerramento chega com a requisição ainda em andamento
+		<-iniciada
+		cancel()
+
+		assert.Equal(t, "concluída", <-resposta)
+		assert.NoError(t, <-encerrado)
+
+		// Depois do encerramento, novas conexões são recusadas
+		_, err = net.Dial("tcp", listener.Addr().String())
+		assert.Error(t, err)
+	})
+
+	t.Run("PrazoDeEncerramentoEsgotado", func(t *testing.T) {
+		iniciada := make(chan struct{})
+		liberar := make(chan struct{})
+		defer close(liberar)
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(iniciada)
+			<-liberar
+		})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		cfg := config.Default().Server
+		cfg.ShutdownTimeout = config.Duration{Duration: 50 * time.Millisecond}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		encerrado := make(chan error, 1)
+		go func() {
+			encerrado <- httpserver.Serve(ctx, httpserver.New(cfg, handler), listener, cfg)
+		}()
+		go http.Get("http://" + listener.Addr().String())
+
+		<-iniciada
+		cancel()
+		assert.ErrorIs(t, <-encerrado, context.DeadlineExceeded)
+	})
+}
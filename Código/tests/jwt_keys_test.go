@@ -0,0 +1,209 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/repository"
+	"go-api/usecase"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKeyPEM(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "private.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	return path
+}
+
+func writePublicKeyPEM(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "public.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return path
+}
+
+func useKeySet(t *testing.T, cfg config.KeysConfig) *config.KeySet {
+	ks, err := config.LoadKeySet(cfg)
+	require.NoError(t, err)
+	return ks
+}
+
+func tokenHeader(t *testing.T, token string) map[string]interface{} {
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &config.Claims{})
+	require.NoError(t, err)
+	return parsed.Header
+}
+
+func TestSigningAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	cases := map[string]config.KeyConfig{
+		"HS256": {Kid: "hs", Algorithm: "HS256", Secret: strings.Repeat("s", 32)},
+		"RS256": {Kid: "rs", Algorithm: "RS256", PrivateKeyFile: writePrivateKeyPEM(t, rsaKey)},
+		"ES256": {Kid: "es", Algorithm: "ES256", PrivateKeyFile: writePrivateKeyPEM(t, ecKey)},
+		"EdDSA": {Kid: "ed", Algorithm: "EdDSA", PrivateKeyFile: writePrivateKeyPEM(t, edKey)},
+	}
+
+	for alg, key := range cases {
+		t.Run(alg, func(t *testing.T) {
+			ks := useKeySet(t, config.KeysConfig{Keys: []config.KeyConfig{key}})
+
+			token, err := ks.GenerateToken(3, "membro", "", time.Hour)
+			require.NoError(t, err)
+
+			header := tokenHeader(t, token)
+			assert.Equal(t, alg, header["alg"])
+			assert.Equal(t, key.Kid, header["kid"])
+
+			claims, err := ks.ParseToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, claims.UserId)
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, newPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	ks := useKeySet(t, config.KeysConfig{Keys: []config.KeyConfig{
+		{Kid: "2024", Algorithm: "ES256", PrivateKeyFile: writePrivateKeyPEM(t, oldKey)},
+	}})
+	oldToken, err := ks.GenerateToken(3, "membro", "", time.Hour)
+	require.NoError(t, err)
+
+	// A chave antiga passa a apenas validar e a nova assina os tokens emitidos daqui em diante
+	ks = useKeySet(t, config.KeysConfig{
+		Active: "2025",
+		Keys: []config.KeyConfig{
+			{Kid: "2024", Algorithm: "ES256", PublicKeyFile: writePublicKeyPEM(t, &oldKey.PublicKey)},
+			{Kid: "2025", Algorithm: "EdDSA", PrivateKeyFile: writePrivateKeyPEM(t, newPrivate)},
+		},
+	})
+
+	_, err = ks.ParseToken(oldToken)
+	assert.NoError(t, err)
+
+	newToken, err := ks.GenerateToken(3, "membro", "", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "2025", tokenHeader(t, newToken)["kid"])
+
+	// Após a remoção da chave antiga, seus tokens deixam de ser aceitos
+	ks = useKeySet(t, config.KeysConfig{Keys: []config.KeyConfig{
+		{Kid: "2025", Algorithm: "EdDSA", PrivateKeyFile: writePrivateKeyPEM(t, newPrivate)},
+	}})
+	_, err = ks.ParseToken(oldToken)
+	assert.ErrorIs(t, err, config.ErrTokenInvalido)
+}
+
+func TestRejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	publicPath := writePublicKeyPEM(t, &rsaKey.PublicKey)
+	ks := useKeySet(t, config.KeysConfig{Keys: []config.KeyConfig{
+		{Kid: "rs", Algorithm: "RS256", PrivateKeyFile: writePrivateKeyPEM(t, rsaKey)},
+	}})
+
+	// Token HS256 assinado com a chave pública RSA como segredo, usando o kid da chave RS256
+	publicPEM, _ := os.ReadFile(publicPath)
+	claims := config.Claims{
+		UserId: 3,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "abc",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "rs"
+	token, _ := forged.SignedString(publicPEM)
+
+	_, err := ks.ParseToken(token)
+	assert.ErrorIs(t, err, config.ErrTokenInvalido)
+}
+
+func TestLoadKeySetValidation(t *testing.T) {
+	_, err := config.LoadKeySet(config.KeysConfig{Keys: []config.KeyConfig{{Kid: "hs", Algorithm: "HS256", Secret: "curto"}}})
+	assert.Error(t, err)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, err = config.LoadKeySet(config.KeysConfig{
+		Active: "rs",
+		Keys:   []config.KeyConfig{{Kid: "rs", Algorithm: "RS256", PublicKeyFile: writePublicKeyPEM(t, &rsaKey.PublicKey)}},
+	})
+	assert.Error(t, err, "a chave ativa precisa poder assinar")
+
+	_, err = config.LoadKeySet(config.KeysConfig{
+		Active: "outra",
+		Keys:   []config.KeyConfig{{Kid: "hs", Algorithm: "HS256", Secret: strings.Repeat("s", 32)}},
+	})
+	assert.Error(t, err)
+}
+
+func TestJWKSEndpoint(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ks := useKeySet(t, config.KeysConfig{
+		Active: "rs",
+		Keys: []config.KeyConfig{
+			{Kid: "rs", Algorithm: "RS256", PrivateKeyFile: writePrivateKeyPEM(t, rsaKey)},
+			{Kid: "es", Algorithm: "ES256", PublicKeyFile: writePublicKeyPEM(t, &ecKey.PublicKey)},
+			{Kid: "hs", Algorithm: "HS256", Secret: strings.Repeat("s", 32)},
+		},
+	})
+
+	db, _ := ConnectMockDB()
+	authUsecase := newAuthUsecase(db)
+	authUsecase.Tokens = usecase.NewTokenUseCase(repository.NewTokenRepository(db), repository.NewRefreshTokenRepository(db), repository.NewSessaoRepository(db), cfgTeste.JWT, ks)
+	router := gin.Default()
+	router.GET("/.well-known/jwks.json", controller.NewAuthController(authUsecase).JWKS)
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var jwks config.JWKSet
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &jwks))
+
+	kids := []string{}
+	for _, key := range jwks.Keys {
+		kids = append(kids, key.Kid)
+		assert.Equal(t, "sig", key.Use)
+	}
+	assert.Equal(t, []string{"es", "rs"}, kids, "chaves HS256 não podem ser publicadas")
+	assert.Equal(t, "EC", jwks.Keys[0].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[0].Crv)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+
+	// Um serviço externo consegue validar o token a partir do JWKS publicado
+	n, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[1].N)
+	published := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+	token, _ := ks.GenerateToken(3, "membro", "", time.Hour)
+	_, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return published, nil })
+	assert.NoError(t, err)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/db"
+	"go-api/httpserver"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// httptest.NewRequest usa 192.0.2.1 como endereço do cliente
+const ipTeste = "192.0.2.1"
+
+// TempoProximo confere se o argumento é um horário a no máximo alguns segundos de now + d
+type TempoProximo time.Duration
+
+func (tp TempoProximo) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+	if !ok {
+		return false
+	}
+	diff := time.Until(t) - time.Duration(tp)
+	return diff > -5*time.Second && diff < 5*time.Second
+}
+
+func setupLoginRouter() (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock := ConnectMockDB()
+	router := gin.Default()
+
+	authUsecase := newAuthUsecase(db)
+	authController := controller.NewAuthController(authUsecase)
+	router.Use(middleware.Auth(authUsecase, "/auth/login"))
+
+	router.POST("/auth/login", authController.Login)
+	router.DELETE("/usuario/:usuarioId/bloqueio", middleware.RequireRole(model.PapelAdmin), authController.DesbloquearUsuario)
+
+	return router, mock
+}
+
+func doLogin(router *gin.Engine, login, senha string) *httptest.ResponseRecorder {
+	body := `{"login":"` + login + `","senha":"` + senha + `"}`
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestLoginAtraso(t *testing.T) {
+	assert.Equal(t, time.Duration(0), loginTeste.Atraso(1, 5))
+	assert.Equal(t, time.Duration(0), loginTeste.Atraso(2, 5))
+	assert.Equal(t, time.Second, loginTeste.Atraso(3, 5))
+	assert.Equal(t, 2*time.Second, loginTeste.Atraso(4, 5))
+	assert.Equal(t, loginTeste.BloqueioDuracao.Duration, loginTeste.Atraso(5, 5))
+	assert.Equal(t, config.LoginAtrasoMaximo, loginTeste.Atraso(19, 20))
+	assert.Equal(t, loginTeste.BloqueioDuracao.Duration, loginTeste.Atraso(20, 20))
+}
+
+func TestLoginProtection(t *testing.T) {
+	chaves := []string{"login:joao", "ip:" + ipTeste}
+
+	t.Run("BloqueiaAoAtingirOLimite", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+
+		expectSemTentativas(mock, chaves...)
+		hash, _ := senhasTeste.Hash("senha123")
+		expectUsuarioByLogin(mock, "joao", hash)
+		expectFalhaRegistrada(mock, "login:joao", loginTeste.MaxFalhas)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE tentativa_login SET bloqueado_ate = ? WHERE chave = ? AND bloqueado_ate < ?")).
+			WithArgs(TempoProximo(loginTeste.BloqueioDuracao.Duration), "login:joao", TempoProximo(loginTeste.BloqueioDuracao.Duration)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectFalhaRegistrada(mock, "ip:"+ipTeste, 1)
+
+		resp := doLogin(router, "joao", "errada")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LoginBloqueadoRetorna429", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+
+		expectTentativas(mock, sqlmock.NewRows(tentativaLoginColumns).
+			AddRow("login:joao", loginTeste.MaxFalhas, time.Now().Add(10*time.Minute), time.Now()), chaves...)
+
+		// Nem a senha correta é conferida enquanto o bloqueio durar
+		resp := doLogin(router, "joao", "senha123")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+		assert.NoError(t, err)
+		assert.InDelta(t, 600, retryAfter, 2)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("IPBloqueadoRetorna429", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+
+		expectTentativas(mock, sqlmock.NewRows(tentativaLoginColumns).
+			AddRow("ip:"+ipTeste, 4, time.Now().Add(2*time.Second), time.Now()), "login:maria", "ip:"+ipTeste)
+
+		resp := doLogin(router, "maria", "qualquer")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AdminDesbloqueiaUsuario", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+		token, _ := gerarToken(1, model.PapelAdmin, "")
+
+		expectTokenValido(mock, 1, model.PapelAdmin)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM tentativa_login WHERE chave = ?")).
+			WithArgs("login:joao").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doAuthRequestWithMethod(router, "DELETE", "/usuario/7/bloqueio", "Bearer "+token)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MembroNaoDesbloqueia", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+		token, _ := gerarToken(7, model.PapelMembro, "")
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+
+		resp := doAuthRequestWithMethod(router, "DELETE", "/usuario/7/bloqueio", "Bearer "+token)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// Sem proxies confiáveis, um X-Forwarded-For forjado não muda o IP contado nas falhas: o
+// atacante não escapa do limite por IP nem bloqueia o IP de outra pessoa
+func TestLoginIgnoraXForwardedForForjado(t *testing.T) {
+	conn := ConnectSQLiteDB(t)
+	router, err := httpserver.NewRouter(config.Default().Server)
+	require.NoError(t, err)
+	router.POST("/auth/login", controller.NewAuthController(newAuthUsecase(conn)).Login)
+
+	for _, forjado := range []string{"203.0.113.7", "203.0.113.8", "198.51.100.1"} {
+		body := `{"login":"joao","senha":"errada"}`
+		req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forjado)
+		req.Header.Set("X-Real-IP", forjado)
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	}
+
+	rows, err := conn.Query("SELECT chave, falhas FROM tentativa_login WHERE chave LIKE 'ip:%'")
+	require.NoError(t, err)
+	defer rows.Close()
+	falhas := map[string]int{}
+	for rows.Next() {
+		var chave string
+		var n int
+		require.NoError(t, rows.Scan(&chave, &n))
+		falhas[chave] = n
+	}
+	assert.Equal(t, map[string]int{"ip:" + ipTeste: 3}, falhas)
+}
+
+// Falhas simultâneas são todas contadas e nenhuma delas falha ao criar a chave pela primeira vez
+func TestRegistrarFalhaConcorrente(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = filepath.Join(t.TempDir(), "api.db")
+	conn, err := db.ConnectDB(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	migrar(t, conn, cfg.Driver)
+
+	repo := repository.NewTentativaLoginRepository(conn)
+	tentativas := usecase.NewTentativaLoginUsecase(repo, loginTeste)
+
+	const n = 50
+	var wg sync.WaitGroup
+	largada := make(chan struct{})
+	erros := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-largada
+			erros <- tentativas.RegistrarFalha("joao", ipTeste)
+		}()
+	}
+	close(largada)
+	wg.Wait()
+	close(erros)
+	for err := range erros {
+		assert.NoError(t, err)
+	}
+
+	atuais, err := repo.GetTentativas("login:joao", "ip:"+ipTeste)
+	require.NoError(t, err)
+	require.Len(t, atuais, 2)
+	for _, tentativa := range atuais {
+		assert.Equal(t, n, tentativa.Falhas, tentativa.Chave)
+	}
+	var bloqueio *usecase.BloqueioError
+	assert.ErrorAs(t, tentativas.Verificar("joao", ipTeste), &bloqueio)
+}
+
+// Falhas anteriores à janela não contam: a contagem recomeça do zero no banco
+func TestRegistrarFalhaForaDaJanela(t *testing.T) {
+	conn := ConnectSQLiteDB(t)
+	repo := repository.NewTentativaLoginRepository(conn)
+	tentativas := usecase.NewTentativaLoginUsecase(repo, loginTeste)
+
+	antiga := time.Now().Add(-config.LoginJanelaFalhas - time.Minute)
+	_, err := conn.Exec("INSERT INTO tentativa_login (chave, falhas, bloqueado_ate, atualizado_em) VALUES (?, ?, ?, ?)",
+		"login:joao", loginTeste.MaxFalhas-1, antiga, antiga)
+	require.NoError(t, err)
+
+	require.NoError(t, tentativas.RegistrarFalha("joao", ""))
+
+	atuais, err := repo.GetTentativas("login:joao")
+	require.NoError(t, err)
+	require.Len(t, atuais, 1)
+	assert.Equal(t, 1, atuais[0].Falhas)
+	assert.NoError(t, tentativas.Verificar("joao", ""))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"go-api/config"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// cfgTeste é a configuração padrão com o custo mínimo do bcrypt, para que os testes que
+	// geram hashes de senha não fiquem lentos
+	cfgTeste    = configTeste()
+	senhasTeste = cfgTeste.Password
+	loginTeste  = cfgTeste.LoginProtection
+
+	// chavesTeste assina os tokens de todos os usecases montados nos testes
+	chavesTeste = chavesDeTeste()
+)
+
+func configTeste() *config.Config {
+	cfg := config.Default()
+	cfg.Password.Cost = bcrypt.MinCost
+	return cfg
+}
+
+func chavesDeTeste() *config.KeySet {
+	ks, err := config.LoadKeySet(config.KeysConfig{Keys: []config.KeyConfig{{Kid: "teste", Algorithm: "HS256", Secret: strings.Repeat("t", 32)}}})
+	if err != nil {
+		panic(err)
+	}
+	return ks
+}
+
+// gerarToken emite um token de acesso com as chaves e a duração usadas pelos testes
+func gerarToken(userId int, papel string, sessionId string) (string, error) {
+	return chavesTeste.GenerateToken(userId, papel, sessionId, cfgTeste.JWT.AccessTokenDuration.Duration)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Os usecases de usuário e tarefa rodam sobre os stores em memória, sem banco nem sqlmock
+func TestTarefaUsecaseMemoria(t *testing.T) {
+	usuarios := repository.NewUsuarioMemoryStore()
+	tarefaUsecase := usecase.NewTarefaUseCase(repository.NewTarefaMemoryStore(usuarios))
+
+	membroId, err := usuarios.CreateUsuario(model.Usuario{Nome: "João", Login: "joao", Senha: "hash", Papel: model.PapelMembro, Status: model.StatusAtivo})
+	require.NoError(t, err)
+	adminId, err := usuarios.CreateUsuario(model.Usuario{Nome: "Maria", Login: "maria", Senha: "hash", Papel: model.PapelAdmin, Status: model.StatusAtivo})
+	require.NoError(t, err)
+	membro := model.Caller{UsuarioId: membroId, Papel: model.PapelMembro}
+	admin := model.Caller{UsuarioId: adminId, Papel: model.PapelAdmin}
+
+	tarefa, err := tarefaUsecase.CreateTarefa(membro, model.Tarefa{Nome: "Estudar Go", Conteudo: "Interfaces", Finalizado: "N"})
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(membroId), tarefa.UsuarioResp)
+
+	_, err = tarefaUsecase.CreateTarefa(membro, model.Tarefa{Nome: "Alheia", UsuarioResp: strconv.Itoa(adminId)})
+	assert.ErrorIs(t, err, usecase.ErrResponsavelProibido)
+	_, err = tarefaUsecase.CreateTarefa(admin, model.Tarefa{Nome: "Órfã", UsuarioResp: "999"})
+	assert.ErrorIs(t, err, usecase.ErrResponsavelInexistente)
+
+	_, err = tarefaUsecase.CreateTarefa(admin, model.Tarefa{Nome: "Da Maria", Finalizado: "N"})
+	require.NoError(t, err)
+
+	tarefas, err := tarefaUsecase.GetTarefas(membro)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Tarefa{tarefa}, tarefas)
+	tarefas, err = tarefaUsecase.GetTarefas(admin)
+	require.NoError(t, err)
+	assert.Len(t, tarefas, 2)
+
+	encontrada, err := tarefaUsecase.GetTarefaById(admin, tarefa.Id)
+	require.NoError(t, err)
+	assert.Equal(t, &tarefa, encontrada)
+}
+
+func TestUsuarioUsecaseMemoria(t *testing.T) {
+	usuarioUsecase := usecase.NewUsuarioUseCase(repository.NewUsuarioMemoryStore(), newTokenUsecase(ConnectSQLiteDB(t)), senhasTeste)
+
+	usuario, err := usuarioUsecase.CreateUsuario(model.Usuario{Nome: "João", Login: "joao", Senha: "senha123"})
+	require.NoError(t, err)
+	assert.Equal(t, model.PapelMembro, usuario.Papel)
+	assert.Equal(t, model.StatusAtivo, usuario.Status)
+
+	_, err = usuarioUsecase.CreateUsuario(model.Usuario{Nome: "Outro", Login: "joao", Senha: "senha123"})
+	assert.ErrorIs(t, err, usecase.ErrLoginEmUso)
+
+	assert.NoError(t, usuarioUsecase.UpdatePapelById(usuario.Id, model.PapelAdmin))
+	assert.ErrorIs(t, usuarioUsecase.UpdatePapelById(usuario.Id, "dono"), usecase.ErrPapelInvalido)
+
+	salvo, err := usuarioUsecase.GetUsuarioById(usuario.Id)
+	require.NoError(t, err)
+	assert.Equal(t, model.PapelAdmin, salvo.Papel)
+}
+
+func TestMemoryStoreConcorrente(t *testing.T) {
+	usuarios := repository.NewUsuarioMemoryStore()
+	tarefas := repository.NewTarefaMemoryStore(usuarios)
+	usuarioId, err := usuarios.CreateUsuario(model.Usuario{Nome: "João", Login: "joao", Papel: model.PapelMembro, Status: model.StatusAtivo})
+	require.NoError(t, err)
+	responsavel := strconv.Itoa(usuarioId)
+
+	const total = 50
+	var wg sync.WaitGroup
+	ids := make(chan int, total)
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := tarefas.CreateTarefa(model.Tarefa{Nome: "Tarefa " + strconv.Itoa(i), UsuarioResp: responsavel, Finalizado: "N"})
+			assert.NoError(t, err)
+			ids <- id
+			_, err = tarefas.GetTarefasByUsuarioId(responsavel)
+			assert.NoError(t, err)
+			assert.NoError(t, usuarios.UpdateSenhaById(usuarioId, strconv.Itoa(i)))
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	// Cada tarefa recebeu um id diferente
+	unicos := map[int]bool{}
+	for id := range ids {
+		unicos[id] = true
+	}
+	assert.Len(t, unicos, total)
+
+	lista, err := tarefas.GetTarefasByUsuarioId(responsavel)
+	require.NoError(t, err)
+	assert.Len(t, lista, total)
+}
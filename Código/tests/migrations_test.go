@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"go-api/config"
+	"go-api/db"
+	"go-api/migrations"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsUpDown(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = ":memory:"
+	conn, err := db.ConnectDB(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+
+	pendentes, err := migrator.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pendentes, 2)
+
+	aplicadas, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, aplicadas, 2)
+	assert.Equal(t, 1, aplicadas[0].Versao)
+	assert.Equal(t, 2, aplicadas[1].Versao)
+	assert.Equal(t, 2, migrator.Versao())
+
+	// Rodar de novo não aplica nada
+	aplicadas, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, aplicadas)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	for _, s := range status {
+		assert.NotNil(t, s.AplicadaEm, s.Nome)
+	}
+
+	revertidas, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, revertidas, 1)
+	assert.Equal(t, 2, revertidas[0].Versao)
+	_, err = conn.Exec("SELECT 1 FROM sessao")
+	assert.Error(t, err, "a tabela sessao deveria ter sido removida")
+
+	pendentes, err = migrator.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pendentes, 1)
+	assert.Equal(t, 2, pendentes[0].Versao)
+
+	aplicadas, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, aplicadas, 1)
+}
+
+// Um banco migrado por uma versão mais nova da API recusa a inicialização
+func TestMigrationsEsquemaMaisNovo(t *testing.T) {
+	ctx := context.Background()
+	conn := ConnectSQLiteDB(t)
+	_, err := conn.Exec("INSERT INTO schema_migrations (versao, nome, aplicada_em) VALUES (?, ?, ?)", 99, "futura", "2030-01-01 00:00:00")
+	require.NoError(t, err)
+
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+	_, err = migrator.Pending(ctx)
+	assert.ErrorIs(t, err, migrations.ErrEsquemaMaisNovo)
+	_, err = migrator.Up(ctx)
+	assert.ErrorIs(t, err, migrations.ErrEsquemaMaisNovo)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, 3)
+	assert.Equal(t, 99, status[2].Versao)
+	assert.Empty(t, status[2].Nome)
+}
+
+// Status e Pending rodam a cada verificação de /readyz e não podem executar DDL
+func TestMigrationsStatusSomenteLeitura(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = ":memory:"
+	conn, err := db.ConnectDB(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+
+	status, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, 2)
+	assert.Nil(t, status[0].AplicadaEm)
+	pendentes, err := migrator.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pendentes, 2)
+
+	var tabelas int
+	require.NoError(t, conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&tabelas))
+	assert.Zero(t, tabelas, "schema_migrations não deveria ter sido criada")
+}
+
+// Um banco com tabelas criadas à mão não é adotado: a primeira migration falha e nada é registrado
+func TestMigrationsEsquemaExistente(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = ":memory:"
+	conn, err := db.ConnectDB(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = conn.Exec("CREATE TABLE usuario (id INTEGER PRIMARY KEY, nome TEXT, login TEXT, senha TEXT, ativo TEXT)")
+	require.NoError(t, err)
+
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	assert.Error(t, err)
+
+	pendentes, err := migrator.Pending(ctx)
+	require.NoError(t, err)
+	assert.Len(t, pendentes, 2)
+}
+
+// Todos os dialetos têm as mesmas versões, para que o esquema evolua junto nos backends
+func TestMigrationsDialetos(t *testing.T) {
+	esperadas, err := migrations.Load(config.DriverSQLite)
+	require.NoError(t, err)
+	require.NotEmpty(t, esperadas)
+
+	for _, driver := range []string{config.DriverMySQL, config.DriverPostgres, config.DriverMemory} {
+		migrationList, err := migrations.Load(driver)
+		require.NoError(t, err, driver)
+		require.Len(t, migrationList, len(esperadas), driver)
+		for i, m := range migrationList {
+			assert.Equal(t, esperadas[i].Versao, m.Versao, driver)
+			assert.Equal(t, esperadas[i].Nome, m.Nome, driver)
+		}
+	}
+
+	_, err = migrations.Load("oracle")
+	assert.Error(t, err)
+}
+
+// Instâncias iniciando juntas sobre o mesmo banco aplicam cada migration uma única vez
+func TestMigrationsConcorrentes(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = filepath.Join(t.TempDir(), "api.db")
+
+	const instancias = 4
+	var wg sync.WaitGroup
+	aplicadas := make(chan int, instancias*2)
+	for i := 0; i < instancias; i++ {
+		conn, err := db.ConnectDB(ctx, cfg)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		migrator, err := migrations.New(conn, cfg.Driver)
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			migrationList, err := migrator.Up(ctx)
+			assert.NoError(t, err)
+			for _, m := range migrationList {
+				aplicadas <- m.Versao
+			}
+		}()
+	}
+	wg.Wait()
+	close(aplicadas)
+
+	var versoes []int
+	for versao := range aplicadas {
+		versoes = append(versoes, versao)
+	}
+	assert.ElementsMatch(t, []int{1, 2}, versoes)
+}
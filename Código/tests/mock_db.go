@@ -2,8 +2,10 @@ package main
 
 import (
 	"database/sql"
+	"database/sql/driver"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func ConnectMockDB() (*sql.DB, sqlmock.Sqlmock) {
@@ -13,3 +15,11 @@ func ConnectMockDB() (*sql.DB, sqlmock.Sqlmock) {
 	}
 	return db, mock
 }
+
+// BcryptOf confere se o argumento enviado ao banco é um hash bcrypt da senha informada
+type BcryptOf string
+
+func (b BcryptOf) Match(v driver.Value) bool {
+	hash, ok := v.(string)
+	return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte(b)) == nil
+}
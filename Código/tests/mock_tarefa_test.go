@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"go-api/controller"
+	"go-api/middleware"
 	"go-api/model"
 	"go-api/repository"
 	"go-api/usecase"
@@ -19,8 +20,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func setupTarefaRouter(db *sql.DB) *gin.Engine {
+// setupTarefaRouter simula o middleware de autenticação identificando a requisição como caller
+func setupTarefaRouter(db *sql.DB, caller model.Caller) *gin.Engine {
 	router := gin.Default()
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set(middleware.CallerKey, caller)
+	})
 
 	tarefaRepository := repository.NewTarefaRepository(db)
 	tarefaUsecase := usecase.NewTarefaUseCase(tarefaRepository)
@@ -38,7 +43,7 @@ func setupTarefaRouter(db *sql.DB) *gin.Engine {
 
 func TestTarefaEndpoints(t *testing.T) {
 	db, mock := ConnectMockDB()
-	router := setupTarefaRouter(db)
+	router := setupTarefaRouter(db, model.Caller{UsuarioId: 1, Papel: model.PapelMembro})
 
 	t.Run("CreateTarefa", func(t *testing.T) {
 		testCreateTarefa(t, router, mock)
@@ -69,7 +74,7 @@ func testCreateTarefa(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 		WithArgs("Estudar Go", "Estudar interfaces", "1", "N").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	tarefa := model.Tarefa{
+	tarefa := model.TarefaRequest{
 		Nome:        "Estudar Go",
 		Conteudo:    "Estudar interfaces",
 		UsuarioResp: "1",
@@ -87,7 +92,8 @@ func testCreateTarefa(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 }
 
 func testGetTarefas(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectQuery("SELECT id, nome, conteudo, usuario_responsavel, finalizado FROM tarefa").
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, conteudo, usuario_responsavel, finalizado FROM tarefa WHERE usuario_responsavel = ? AND ativo = 'A'")).
+		WithArgs("1").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "conteudo", "usuario_responsavel", "finalizado"}).
 			AddRow(1, "Estudar Go", "Estudar interfaces", "1", "N"))
 
@@ -99,12 +105,16 @@ func testGetTarefas(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 	fmt.Println("✔️ GetTarefas OK")
 }
 
-func testGetTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectPrepare("SELECT id, nome, conteudo, usuario_responsavel, finalizado FROM tarefa WHERE id = ?").
+func expectTarefaById(mock sqlmock.Sqlmock, id int, usuarioResp string) {
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, conteudo, usuario_responsavel, finalizado FROM tarefa WHERE id = ?")).
 		ExpectQuery().
-		WithArgs(1).
+		WithArgs(id).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "conteudo", "usuario_responsavel", "finalizado"}).
-			AddRow(1, "Estudar Go", "Estudar interfaces", "1", "N"))
+			AddRow(id, "Estudar Go", "Estudar interfaces", usuarioResp, "N"))
+}
+
+func testGetTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
+	expectTarefaById(mock, 1, "1")
 
 	req, _ := http.NewRequest("GET", "/tarefa/1", nil)
 	resp := httptest.NewRecorder()
@@ -115,12 +125,13 @@ func testGetTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 }
 
 func testUpdateTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
+	expectTarefaById(mock, 1, "1")
 	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE tarefa SET nome = ?, conteudo = ?, usuario_responsavel = ?, finalizado = ? WHERE id = ?")).
 		ExpectExec().
 		WithArgs("Go Avançado", "Estudar reflect", "1", "S", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	tarefa := model.Tarefa{
+	tarefa := model.TarefaRequest{
 		Nome:        "Go Avançado",
 		Conteudo:    "Estudar reflect",
 		UsuarioResp: "1",
@@ -138,6 +149,7 @@ func testUpdateTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock
 }
 
 func testSoftDeleteTarefaById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
+	expectTarefaById(mock, 1, "1")
 	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE tarefa SET ativo = 'N' WHERE id = ? AND ativo = 'A'")).
 		ExpectExec().
 		WithArgs(1).
@@ -16,6 +16,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,7 +24,7 @@ func setupRouter(db *sql.DB) *gin.Engine {
 	router := gin.Default()
 
 	usuarioRepository := repository.NewUsuarioRepository(db)
-	usuarioUsecase := usecase.NewUsuarioUseCase(usuarioRepository)
+	usuarioUsecase := usecase.NewUsuarioUseCase(usuarioRepository, newTokenUsecase(db), senhasTeste)
 	usuarioController := controller.NewUsuarioController(usuarioUsecase)
 
 	router.GET("/usuarios", usuarioController.GetUsuarios)
@@ -62,10 +63,10 @@ func TestUsuarioEndpoints(t *testing.T) {
 
 func testCreateUsuario(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 	mock.ExpectExec("INSERT INTO usuario").
-		WithArgs("Teste User", "testeuser", "123456").
+		WithArgs("Teste User", "testeuser", BcryptOf("123456"), "membro", "A").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	usuario := model.Usuario{
+	usuario := model.UsuarioRequest{
 		Nome:  "Teste User",
 		Login: "testeuser",
 		Senha: "123456",
@@ -78,51 +79,50 @@ func testCreateUsuario(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "senha")
 	fmt.Println("✔️ CreateUsuario OK")
 }
 
 func testGetUsuarios(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectQuery("SELECT id, nome, login, senha FROM usuario").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha"}).
-			AddRow(1, "João", "joao", "senha123"))
+	mock.ExpectQuery("SELECT id, nome, login, senha, papel, ativo FROM usuario").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(1, "João", "joao", "senha123", "membro", "A"))
 
 	req, _ := http.NewRequest("GET", "/usuarios", nil)
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "senha")
 	fmt.Println("✔️ GetUsuarios OK")
 }
 
 func testGetUsuarioById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectPrepare("SELECT id, nome, login, senha FROM usuario WHERE id = ?").
+	mock.ExpectPrepare("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?").
 		ExpectQuery().
 		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha"}).
-			AddRow(1, "João", "joao", "senha123"))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(1, "João", "joao", "senha123", "membro", "A"))
 
 	req, _ := http.NewRequest("GET", "/usuario/1", nil)
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"id_usuario":1,"nome_usuario":"João","login_usuario":"joao","papel_usuario":"membro","status_usuario":"ativo"}`, resp.Body.String())
 	fmt.Println("✔️ GetUsuarioById OK")
 }
 
 func testUpdateUsuarioById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET nome = ?, login = ?, senha = ? WHERE id = ?")).
+	// A senha enviada é ignorada: só /auth/change-password troca a senha
+	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET nome = ?, login = ? WHERE id = ?")).
 		ExpectExec().
-		WithArgs("User Atualizado", "usuarioatualizado", "novaSenha123", 1).
+		WithArgs("User Atualizado", "usuarioatualizado", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	update := model.Usuario{
-		Nome:  "User Atualizado",
-		Login: "usuarioatualizado",
-		Senha: "novaSenha123",
-	}
-	body, _ := json.Marshal(update)
+	body := `{"nome_usuario":"User Atualizado","login_usuario":"usuarioatualizado","senha_usuario":"novaSenha123"}`
 
-	req, _ := http.NewRequest("PUT", "/usuario/1", bytes.NewBuffer(body))
+	req, _ := http.NewRequest("PUT", "/usuario/1", bytes.NewBufferString(body))
 	req.Header.Set("Content-Type", "application/json")
 	resp := httptest.NewRecorder()
 	router.ServeHTTP(resp, req)
@@ -132,10 +132,16 @@ func testUpdateUsuarioById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmoc
 }
 
 func testSoftDeleteUsuarioById(t *testing.T, router *gin.Engine, mock sqlmock.Sqlmock) {
-	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo = 'A'")).
+	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo <> 'I'")).
 		ExpectExec().
 		WithArgs(1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND encerrada_em IS NULL")).
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	req, _ := http.NewRequest("DELETE", "/usuario/1", nil)
 	resp := httptest.NewRecorder()
@@ -144,3 +150,80 @@ func testSoftDeleteUsuarioById(t *testing.T, router *gin.Engine, mock sqlmock.Sq
 	assert.Equal(t, http.StatusOK, resp.Code)
 	fmt.Println("✔️ SoftDeleteUsuarioById OK")
 }
+
+// O MySQL sem clientFoundRows responde zero linhas afetadas quando os valores não mudam
+func TestUpdateUsuarioSemAlteracao(t *testing.T) {
+	body := `{"nome_usuario":"João","login_usuario":"joao"}`
+
+	t.Run("UsuarioExistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupRouter(db)
+
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET nome = ?, login = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs("João", "joao", 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM usuario WHERE id = ?")).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		req, _ := http.NewRequest("PUT", "/usuario/1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UsuarioInexistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupRouter(db)
+
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET nome = ?, login = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs("João", "joao", 99).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM usuario WHERE id = ?")).
+			WithArgs(99).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		req, _ := http.NewRequest("PUT", "/usuario/99", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateUsuarioSemSenha(t *testing.T) {
+	db, mock := ConnectMockDB()
+	router := setupRouter(db)
+
+	req, _ := http.NewRequest("POST", "/usuario", bytes.NewBufferString(`{"nome_usuario":"Teste","login_usuario":"teste"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUsuarioLoginEmUso(t *testing.T) {
+	db, mock := ConnectMockDB()
+	router := setupRouter(db)
+
+	mock.ExpectExec("INSERT INTO usuario").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'teste' for key 'usuario.login'"})
+
+	req, _ := http.NewRequest("POST", "/usuario", bytes.NewBufferString(`{"nome_usuario":"Teste","login_usuario":"teste","senha_usuario":"123456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), usecase.ErrLoginEmUso.Error())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/model"
+	"go-api/oidc"
+	"go-api/repository"
+	"go-api/usecase"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcClientId = "go-api"
+
+// idpTeste é um provedor OpenID Connect mínimo: publica discovery e JWKS e troca códigos
+// previamente registrados por ID tokens assinados com a própria chave
+type idpTeste struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu      sync.Mutex
+	codigos map[string]codigoTeste
+}
+
+type codigoTeste struct {
+	challenge string
+	claims    jwt.MapClaims
+	key       *rsa.PrivateKey
+}
+
+func newIdpTeste(t *testing.T) *idpTeste {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &idpTeste{key: key, codigos: make(map[string]codigoTeste)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "idp-1",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *idpTeste) token(w http.ResponseWriter, r *http.Request) {
+	clientId, secret, ok := r.BasicAuth()
+	if !ok || clientId != oidcClientId || secret != "segredo" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	idp.mu.Lock()
+	codigo, ok := idp.codigos[r.PostFormValue("code")]
+	delete(idp.codigos, r.PostFormValue("code"))
+	idp.mu.Unlock()
+
+	if !ok || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != codigo.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, codigo.claims)
+	token.Header["kid"] = "idp-1"
+	idToken, _ := token.SignedString(codigo.key)
+	json.NewEncoder(w).Encode(map[string]string{"access_token": "at", "token_type": "Bearer", "id_token": idToken})
+}
+
+// autorizar simula o usuário aprovando o login no provedor: registra um código para o desafio
+// PKCE da URL de autorização e retorna o código e o state a enviar ao callback
+func (idp *idpTeste) autorizar(t *testing.T, authURL string, claims jwt.MapClaims) (string, string) {
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, oidcClientId, query.Get("client_id"))
+
+	base := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   oidcClientId,
+		"sub":   "sub-123",
+		"nonce": query.Get("nonce"),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Minute).Unix(),
+	}
+	for k, v := range claims {
+		base[k] = v
+	}
+
+	codigo, _ := config.RandomId()
+	idp.mu.Lock()
+	idp.codigos[codigo] = codigoTeste{challenge: query.Get("code_challenge"), claims: base, key: idp.key}
+	idp.mu.Unlock()
+	return codigo, query.Get("state")
+}
+
+func newOidcUsecase(db *sql.DB, idp *idpTeste) *usecase.OidcUsecase {
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       idp.server.URL,
+		ClientId:     oidcClientId,
+		ClientSecret: "segredo",
+		RedirectURL:  "http://localhost:8000/auth/oidc/callback",
+	}, idp.server.Client())
+	return usecase.NewOidcUsecase(provider, repository.NewUsuarioRepository(db), repository.NewIdentidadeExternaRepository(db), newTokenUsecase(db), senhasTeste)
+}
+
+func setupOidcRouter(db *sql.DB, idp *idpTeste) *gin.Engine {
+	return setupOidcRouterCom(newOidcUsecase(db, idp))
+}
+
+func setupOidcRouterCom(oidcUsecase *usecase.OidcUsecase) *gin.Engine {
+	oidcController := controller.NewOidcController(oidcUsecase)
+
+	router := gin.Default()
+	router.GET("/auth/oidc/login", oidcController.Login)
+	router.GET("/auth/oidc/callback", oidcController.Callback)
+	return router
+}
+
+func iniciarOidc(t *testing.T, router *gin.Engine) string {
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/auth/oidc/login", nil)
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusFound, resp.Code)
+	return resp.Header().Get("Location")
+}
+
+func doOidcCallback(router *gin.Engine, codigo, state string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/auth/oidc/callback?"+url.Values{"code": {codigo}, "state": {state}}.Encode(), nil)
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func expectIdentidade(mock sqlmock.Sqlmock, issuer string, usuarioId int) {
+	rows := sqlmock.NewRows([]string{"usuario_id", "issuer", "subject", "criada_em"})
+	if usuarioId != 0 {
+		rows.AddRow(usuarioId, issuer, "sub-123", time.Now())
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT usuario_id, issuer, subject, criada_em FROM usuario_identidade WHERE issuer = ? AND subject = ?")).
+		WithArgs(issuer, "sub-123").
+		WillReturnRows(rows)
+}
+
+func TestOidcLogin(t *testing.T) {
+	idp := newIdpTeste(t)
+
+	t.Run("PrimeiroLoginProvisionaUsuario", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"preferred_username": "maria", "name": "Maria"})
+
+		expectIdentidade(mock, idp.server.URL, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("maria").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario (nome, login, senha, papel, ativo) VALUES (?, ?, ?, ?, ?)")).
+			WithArgs("Maria", "maria", sqlmock.AnyArg(), model.PapelMembro, "A").
+			WillReturnResult(sqlmock.NewResult(9, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario_identidade (usuario_id, issuer, subject, criada_em) VALUES (?, ?, ?, ?)")).
+			WithArgs(9, idp.server.URL, "sub-123", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSessaoIniciada(mock, 9)
+
+		resp := doOidcCallback(router, codigo, state)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		var body model.TokenResponse
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		claims, err := chavesTeste.ParseToken(body.Token)
+		require.NoError(t, err)
+		assert.Equal(t, 9, claims.UserId)
+		assert.Equal(t, model.PapelMembro, claims.Papel)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("FalhaNaIdentidadeRemoveUsuario", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"preferred_username": "maria"})
+
+		expectIdentidade(mock, idp.server.URL, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("maria").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario (nome, login, senha, papel, ativo) VALUES (?, ?, ?, ?, ?)")).
+			WillReturnResult(sqlmock.NewResult(9, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario_identidade (usuario_id, issuer, subject, criada_em) VALUES (?, ?, ?, ?)")).
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM usuario WHERE id = ?")).
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// Dois primeiros logins simultâneos do mesmo sujeito: o segundo encontra o login ocupado
+	// na gravação, depois de já ter passado pela consulta
+	t.Run("PrimeiroLoginSimultaneo", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"preferred_username": "maria"})
+
+		expectIdentidade(mock, idp.server.URL, 0)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("maria").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario (nome, login, senha, papel, ativo) VALUES (?, ?, ?, ?, ?)")).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'maria' for key 'usuario.login'"})
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("IdentidadeExistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), nil)
+
+		expectIdentidade(mock, idp.server.URL, 7)
+		expectUsuarioById(mock, 7, model.PapelAdmin)
+		expectSessaoIniciada(mock, 7)
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LoginLocalExistenteNaoEhLigado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"preferred_username": "joao"})
+
+		expectIdentidade(mock, idp.server.URL, 0)
+		expectUsuarioByLogin(mock, "joao", "hash")
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	// /auth/oidc/login é público: os logins pendentes não crescem sem limite
+	t.Run("LimiteDeLoginsPendentes", func(t *testing.T) {
+		db, _ := ConnectMockDB()
+		oidcUsecase := newOidcUsecase(db, idp)
+		oidcUsecase.MaxPendentes = 2
+		router := setupOidcRouterCom(oidcUsecase)
+
+		iniciarOidc(t, router)
+		iniciarOidc(t, router)
+
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/oidc/login", nil)
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	})
+
+	t.Run("StateDesconhecido", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, _ := idp.autorizar(t, iniciarOidc(t, router), nil)
+
+		resp := doOidcCallback(router, codigo, "forjado")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("StateSoValeUmaVez", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), nil)
+		expectIdentidade(mock, idp.server.URL, 7)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+		expectSessaoIniciada(mock, 7)
+		assert.Equal(t, http.StatusOK, doOidcCallback(router, codigo, state).Code)
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NonceDiferente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"nonce": "outro"})
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		// O motivo da recusa não é revelado ao cliente
+		assert.JSONEq(t, `{"error":"`+usecase.ErrOidcLoginFalhou.Error()+`"}`, resp.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AudienciaDeOutroCliente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), jwt.MapClaims{"aud": "outro-cliente"})
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AssinaturaDeOutraChave", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), nil)
+		outra, _ := rsa.GenerateKey(rand.Reader, 2048)
+		idp.mu.Lock()
+		c := idp.codigos[codigo]
+		c.key = outra
+		idp.codigos[codigo] = c
+		idp.mu.Unlock()
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("VerificadorPKCEIncorreto", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		codigo, state := idp.autorizar(t, iniciarOidc(t, router), nil)
+		idp.mu.Lock()
+		c := idp.codigos[codigo]
+		c.challenge = oidc.CodeChallenge("outro-verificador")
+		idp.codigos[codigo] = c
+		idp.mu.Unlock()
+
+		resp := doOidcCallback(router, codigo, state)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LoginRecusadoNoProvedor", func(t *testing.T) {
+		db, _ := ConnectMockDB()
+		router := setupOidcRouter(db, idp)
+
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/auth/oidc/callback?error=access_denied&state=x", nil)
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"go-api/controller"
+	"go-api/middleware"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRbacRouter(db *sql.DB) *gin.Engine {
+	router := gin.Default()
+
+	usuarioController := controller.NewUsuarioController(usecase.NewUsuarioUseCase(repository.NewUsuarioRepository(db), newTokenUsecase(db), senhasTeste))
+	router.Use(middleware.Auth(newAuthUsecase(db)))
+
+	adminOnly := middleware.RequireRole(model.PapelAdmin)
+	selfOrAdmin := middleware.RequireSelfOrRole("usuarioId", model.PapelAdmin)
+
+	router.GET("/usuarios", adminOnly, usuarioController.GetUsuarios)
+	router.GET("/usuario/:usuarioId", selfOrAdmin, usuarioController.GetUsuarioById)
+	router.PUT("/usuario/:usuarioId/papel", adminOnly, usuarioController.UpdatePapelById)
+
+	return router
+}
+
+func doRbacRequest(router *gin.Engine, method, path, papel string, userId int, body string) *httptest.ResponseRecorder {
+	token, _ := gerarToken(userId, papel, "")
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func expectUsuarioById(mock sqlmock.Sqlmock, id int, papel string) {
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+		ExpectQuery().
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(id, "João", "joao", "hash", papel, "A"))
+}
+
+func TestRoleBasedAccess(t *testing.T) {
+	db, mock := ConnectMockDB()
+	router := setupRbacRouter(db)
+
+	t.Run("MembroNaoListaUsuarios", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		resp := doRbacRequest(router, "GET", "/usuarios", model.PapelMembro, 7, "")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("AdminListaUsuarios", func(t *testing.T) {
+		expectTokenValido(mock, 1, model.PapelAdmin)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+				AddRow(7, "João", "joao", "hash", "membro", "A"))
+
+		resp := doRbacRequest(router, "GET", "/usuarios", model.PapelAdmin, 1, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("MembroConsultaASiMesmo", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectUsuarioById(mock, 7, model.PapelMembro)
+
+		resp := doRbacRequest(router, "GET", "/usuario/7", model.PapelMembro, 7, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("MembroNaoConsultaOutroUsuario", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		resp := doRbacRequest(router, "GET", "/usuario/8", model.PapelMembro, 7, "")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("MembroNaoAlteraOProprioPapel", func(t *testing.T) {
+		expectTokenValido(mock, 7, model.PapelMembro)
+		resp := doRbacRequest(router, "PUT", "/usuario/7/papel", model.PapelMembro, 7, `{"papel_usuario":"admin"}`)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("AdminAlteraPapel", func(t *testing.T) {
+		expectTokenValido(mock, 1, model.PapelAdmin)
+		expectUsuarioById(mock, 8, model.PapelMembro)
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET papel = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs(model.PapelAdmin, 8).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// O papel antigo viaja nos tokens já emitidos, então as sessões são encerradas
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ?")).
+			WithArgs(8).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND encerrada_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 8).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doRbacRequest(router, "PUT", "/usuario/8/papel", model.PapelAdmin, 1, `{"papel_usuario":"admin"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("PapelInvalido", func(t *testing.T) {
+		expectTokenValido(mock, 1, model.PapelAdmin)
+		resp := doRbacRequest(router, "PUT", "/usuario/8/papel", model.PapelAdmin, 1, `{"papel_usuario":"root"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
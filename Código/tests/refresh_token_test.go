@@ -0,0 +1,126 @@
+package main
+
+import (
+	"go-api/config"
+	"go-api/usecase"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var refreshTokenColumns = []string{"id", "token_hash", "usuario_id", "familia", "expira_em", "usado_em", "revogado"}
+
+func expectRefreshTokenByHash(mock sqlmock.Sqlmock, token string, rows *sqlmock.Rows) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, token_hash, usuario_id, familia, expira_em, usado_em, revogado FROM refresh_token WHERE token_hash = ?")).
+		WithArgs(config.HashOpaqueToken(token)).
+		WillReturnRows(rows)
+}
+
+// expectFamiliaRevogada espera a revogação dos refresh tokens da família e o encerramento da sessão
+func expectFamiliaRevogada(mock sqlmock.Sqlmock, familia string) {
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE familia = ?")).
+		WithArgs(familia).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE id = ? AND encerrada_em IS NULL")).
+		WithArgs(sqlmock.AnyArg(), familia).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestRefreshToken(t *testing.T) {
+	t.Run("RotacionaNaMesmaFamilia", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 1, "familia-1", time.Now().Add(time.Hour), nil, "N"))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET usado_em = ? WHERE id = ? AND usado_em IS NULL AND revogado = 'N'")).
+			WithArgs(sqlmock.AnyArg(), 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+			ExpectQuery().
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+				AddRow(1, "João", "joao", "hash", "admin", "A"))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO refresh_token")).
+			WithArgs(sqlmock.AnyArg(), 1, "familia-1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+
+		tokens, err := authUsecase.Refresh("refresh-1")
+		assert.NoError(t, err)
+		assert.NotEqual(t, "refresh-1", tokens.RefreshToken)
+
+		// O novo token de acesso reflete o papel atual do usuário
+		claims, err := chavesTeste.ParseToken(tokens.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, "familia-1", claims.SessionId)
+		assert.Equal(t, "admin", claims.Papel)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ReusoRevogaFamilia", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 1, "familia-1", time.Now().Add(time.Hour), time.Now().Add(-time.Minute), "N"))
+		expectFamiliaRevogada(mock, "familia-1")
+
+		_, err := authUsecase.Refresh("refresh-1")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UsoConcorrenteRevogaFamilia", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 1, "familia-1", time.Now().Add(time.Hour), nil, "N"))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET usado_em = ?")).
+			WithArgs(sqlmock.AnyArg(), 10).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		expectFamiliaRevogada(mock, "familia-1")
+
+		_, err := authUsecase.Refresh("refresh-1")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("FamiliaRevogada", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 1, "familia-1", time.Now().Add(time.Hour), nil, "S"))
+
+		_, err := authUsecase.Refresh("refresh-1")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Expirado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 1, "familia-1", time.Now().Add(-time.Hour), nil, "N"))
+
+		_, err := authUsecase.Refresh("refresh-1")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Inexistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "desconhecido", sqlmock.NewRows(refreshTokenColumns))
+
+		_, err := authUsecase.Refresh("desconhecido")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
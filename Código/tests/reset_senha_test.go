@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/notification"
+	"go-api/repository"
+	"go-api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Captura aceita qualquer argumento e guarda o valor recebido pelo banco
+type Captura struct {
+	Valor driver.Value
+}
+
+func (c *Captura) Match(v driver.Value) bool {
+	c.Valor = v
+	return true
+}
+
+var resetSenhaColumns = []string{"id", "token_hash", "usuario_id", "expira_em", "usado_em"}
+
+func setupResetSenhaRouter(db *sql.DB, outbox *bytes.Buffer) *gin.Engine {
+	router := gin.Default()
+
+	resetSenhaUsecase := usecase.NewResetSenhaUsecase(
+		repository.NewUsuarioRepository(db),
+		repository.NewResetSenhaRepository(db),
+		newTokenUsecase(db),
+		usecase.NewTentativaLoginUsecase(repository.NewTentativaLoginRepository(db), loginTeste),
+		notification.NewWriterSender(outbox),
+		cfgTeste.ResetSenha,
+		senhasTeste,
+	)
+	resetSenhaController := controller.NewResetSenhaController(resetSenhaUsecase)
+
+	router.POST("/auth/forgot-password", resetSenhaController.ForgotPassword)
+	router.POST("/auth/reset-password", resetSenhaController.ResetPassword)
+
+	return router
+}
+
+func doJSONRequest(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func expectResetSenhaByHash(mock sqlmock.Sqlmock, token string, expiraEm time.Time, usadoEm interface{}) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, token_hash, usuario_id, expira_em, usado_em FROM reset_senha WHERE token_hash = ?")).
+		WithArgs(config.HashOpaqueToken(token)).
+		WillReturnRows(sqlmock.NewRows(resetSenhaColumns).
+			AddRow(4, config.HashOpaqueToken(token), 1, expiraEm, usadoEm))
+}
+
+// expectResetSenhaRecente responde quantos tokens do usuário 1 foram emitidos há menos de cinco minutos
+func expectResetSenhaRecente(mock sqlmock.Sqlmock, pendentes int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM reset_senha WHERE usuario_id = ? AND usado_em IS NULL AND expira_em > ?")).
+		WithArgs(1, TempoProximo(cfgTeste.ResetSenha.Duracao.Duration-5*time.Minute)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(pendentes))
+}
+
+func TestForgotPassword(t *testing.T) {
+	t.Run("EnviaTokenDeUsoUnico", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		var outbox bytes.Buffer
+		router := setupResetSenhaRouter(db, &outbox)
+
+		tokenHash := &Captura{}
+		expectUsuarioByLogin(mock, "joao", "hash")
+		expectResetSenhaRecente(mock, 0)
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM reset_senha WHERE usuario_id = ? AND usado_em IS NULL")).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO reset_senha (token_hash, usuario_id, expira_em) VALUES (?, ?, ?)")).
+			WithArgs(tokenHash, 1, TempoProximo(cfgTeste.ResetSenha.Duracao.Duration)).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+
+		resp := doJSONRequest(router, "/auth/forgot-password", `{"login":"joao"}`)
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// O banco guarda apenas o hash do token enviado ao usuário
+		match := regexp.MustCompile(`(?m)^([A-Za-z0-9_-]{43})$`).FindStringSubmatch(outbox.String())
+		require.Len(t, match, 2, outbox.String())
+		assert.Contains(t, outbox.String(), "Para: joao")
+		assert.Equal(t, config.HashOpaqueToken(match[1]), tokenHash.Valor)
+		assert.NotContains(t, outbox.String(), tokenHash.Valor)
+	})
+
+	// Pedidos repetidos não invalidam o link já enviado nem geram novas notificações
+	t.Run("PedidoRepetidoEIgnorado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		var outbox bytes.Buffer
+		router := setupResetSenhaRouter(db, &outbox)
+
+		expectUsuarioByLogin(mock, "joao", "hash")
+		expectResetSenhaRecente(mock, 1)
+
+		resp := doJSONRequest(router, "/auth/forgot-password", `{"login":"joao"}`)
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Empty(t, outbox.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("LoginInexistenteTemAMesmaResposta", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		var outbox bytes.Buffer
+		router := setupResetSenhaRouter(db, &outbox)
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
+			WithArgs("ninguem").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+
+		resp := doJSONRequest(router, "/auth/forgot-password", `{"login":"ninguem"}`)
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+		assert.Empty(t, outbox.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("RedefineSenhaEEncerraSessoes", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupResetSenhaRouter(db, &bytes.Buffer{})
+
+		expectResetSenhaByHash(mock, "token-1", time.Now().Add(time.Minute), nil)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE reset_senha SET usado_em = ? WHERE id = ? AND usado_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUsuarioById(mock, 1, "membro")
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET senha = ? WHERE id = ?")).
+			ExpectExec().
+			WithArgs(BcryptOf("novaSenha123"), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ?")).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND encerrada_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM tentativa_login WHERE chave = ?")).
+			WithArgs("login:joao").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doJSONRequest(router, "/auth/reset-password", `{"token":"token-1","nova_senha":"novaSenha123"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenJaUsado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupResetSenhaRouter(db, &bytes.Buffer{})
+
+		expectResetSenhaByHash(mock, "token-1", time.Now().Add(time.Minute), time.Now().Add(-time.Minute))
+
+		resp := doJSONRequest(router, "/auth/reset-password", `{"token":"token-1","nova_senha":"novaSenha123"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenExpirado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupResetSenhaRouter(db, &bytes.Buffer{})
+
+		expectResetSenhaByHash(mock, "token-1", time.Now().Add(-time.Minute), nil)
+
+		resp := doJSONRequest(router, "/auth/reset-password", `{"token":"token-1","nova_senha":"novaSenha123"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UsoConcorrente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupResetSenhaRouter(db, &bytes.Buffer{})
+
+		expectResetSenhaByHash(mock, "token-1", time.Now().Add(time.Minute), nil)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE reset_senha SET usado_em = ?")).
+			WithArgs(sqlmock.AnyArg(), 4).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		resp := doJSONRequest(router, "/auth/reset-password", `{"token":"token-1","nova_senha":"novaSenha123"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenInexistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupResetSenhaRouter(db, &bytes.Buffer{})
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, token_hash, usuario_id, expira_em, usado_em FROM reset_senha WHERE token_hash = ?")).
+			WillReturnRows(sqlmock.NewRows(resetSenhaColumns))
+
+		resp := doJSONRequest(router, "/auth/reset-password", `{"token":"qualquer","nova_senha":"novaSenha123"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/migrations"
+	"go-api/model"
+	"go-api/usecase"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSaudeRouter(t *testing.T, conn *sql.DB, rotinas *usecase.Rotinas) (*gin.Engine, *usecase.SaudeUsecase) {
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+
+	saudeUsecase := usecase.NewSaudeUsecase(conn, migrator, rotinas, time.Second)
+	saudeController := controller.NewSaudeController(saudeUsecase)
+	router := gin.Default()
+	router.GET("/healthz", saudeController.Healthz)
+	router.GET("/readyz", saudeController.Readyz)
+	return router, saudeUsecase
+}
+
+func getProntidao(t *testing.T, router *gin.Engine) (int, model.Prontidao) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	// /readyz é pública: os erros do driver e os nomes das migrations ficam fora da resposta
+	assert.NotContains(t, w.Body.String(), "erro")
+	assert.NotContains(t, w.Body.String(), "autenticacao")
+
+	var prontidao model.Prontidao
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &prontidao))
+	return w.Code, prontidao
+}
+
+// rodaAteCancelar é uma rotina que só termina no encerramento, como as do servidor
+func rodaAteCancelar(ctx context.Context) {
+	<-ctx.Done()
+}
+
+func TestReadyz(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rotinas := usecase.NewRotinas()
+	rotinas.Run(ctx, "limpeza", rodaAteCancelar)
+	t.Cleanup(func() {
+		cancel()
+		rotinas.Wait()
+	})
+
+	conn := ConnectSQLiteDB(t)
+	router, saudeUsecase := setupSaudeRouter(t, conn, rotinas)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	code, prontidao := getProntidao(t, router)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, model.SaudeOk, prontidao.Status)
+	assert.Equal(t, model.SaudeOk, prontidao.Banco.Status)
+	assert.Positive(t, prontidao.Banco.Pool.MaxAbertas)
+	assert.Positive(t, prontidao.Banco.Pool.Abertas)
+	assert.Equal(t, model.MigrationsResumo{Status: model.SaudeOk, Versao: 2, VersaoEsperada: 2}, prontidao.Migrations)
+	assert.Equal(t, []model.RotinaResumo{{Nome: "limpeza", Status: model.RotinaExecutando}}, prontidao.Rotinas)
+
+	t.Run("MigrationPendente", func(t *testing.T) {
+		migrator, err := migrations.New(conn, config.DriverSQLite)
+		require.NoError(t, err)
+		_, err = migrator.Down(context.Background(), 1)
+		require.NoError(t, err)
+		t.Cleanup(func() { migrator.Up(context.Background()) })
+
+		code, prontidao := getProntidao(t, router)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, model.SaudeIndisponivel, prontidao.Status)
+		assert.Equal(t, model.MigrationsResumo{Status: model.SaudeIndisponivel, Versao: 1, VersaoEsperada: 2, Pendentes: 1}, prontidao.Migrations)
+		assert.Equal(t, model.SaudeOk, prontidao.Banco.Status)
+
+		diagnostico := saudeUsecase.Diagnostico(context.Background())
+		assert.Equal(t, []string{"0002_autenticacao"}, diagnostico.Migrations.Pendentes)
+	})
+
+	t.Run("BancoFora", func(t *testing.T) {
+		conn := ConnectSQLiteDB(t)
+		router, saudeUsecase := setupSaudeRouter(t, conn, rotinas)
+		require.NoError(t, conn.Close())
+
+		code, prontidao := getProntidao(t, router)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, model.SaudeIndisponivel, prontidao.Banco.Status)
+		assert.NotEmpty(t, saudeUsecase.Diagnostico(context.Background()).Banco.Erro)
+	})
+
+	// /healthz não depende do banco
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// Uma rotina que termina antes do encerramento, ou entra em pânico, tira a instância de circulação
+func TestReadyzRotinas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rotinas := usecase.NewRotinas()
+	rotinas.Run(ctx, "limpeza", rodaAteCancelar)
+	rotinas.Run(ctx, "encerrada", func(ctx context.Context) {})
+	rotinas.Run(ctx, "com-panico", func(ctx context.Context) { panic("falha inesperada") })
+
+	router, saudeUsecase := setupSaudeRouter(t, ConnectSQLiteDB(t), rotinas)
+	assert.Eventually(t, func() bool {
+		status := rotinas.Status()
+		return status[1].Status != model.RotinaExecutando && status[2].Status != model.RotinaExecutando
+	}, time.Second, 10*time.Millisecond)
+
+	code, prontidao := getProntidao(t, router)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	require.Len(t, prontidao.Rotinas, 3)
+	assert.Equal(t, model.RotinaExecutando, prontidao.Rotinas[0].Status)
+	assert.Equal(t, model.RotinaEncerrada, prontidao.Rotinas[1].Status)
+	assert.Equal(t, model.RotinaFalhou, prontidao.Rotinas[2].Status)
+	assert.Equal(t, "falha inesperada", saudeUsecase.Diagnostico(context.Background()).Rotinas[2].Erro)
+
+	cancel()
+	rotinas.Wait()
+	assert.Equal(t, model.RotinaEncerrada, rotinas.Status()[0].Status)
+}
+
+// capturaStdout retorna o que fn escreveu na saída padrão, onde o servidor registra seus logs
+func capturaStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	anterior := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = anterior }()
+
+	fn()
+	require.NoError(t, w.Close())
+	saida, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(saida)
+}
+
+// O diagnóstico vai para o log quando a situação muda, não a cada verificação do balanceador
+func TestReadyzLogSoNaMudanca(t *testing.T) {
+	conn := ConnectSQLiteDB(t)
+	_, saudeUsecase := setupSaudeRouter(t, conn, usecase.NewRotinas())
+	migrator, err := migrations.New(conn, config.DriverSQLite)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.Empty(t, capturaStdout(t, func() { saudeUsecase.Prontidao(ctx) }))
+
+	_, err = migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	saida := capturaStdout(t, func() {
+		for i := 0; i < 5; i++ {
+			saudeUsecase.Prontidao(ctx)
+		}
+	})
+	assert.Equal(t, 1, strings.Count(saida, "Instância indisponível"))
+	assert.Contains(t, saida, "0002_autenticacao")
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	saida = capturaStdout(t, func() {
+		saudeUsecase.Prontidao(ctx)
+		saudeUsecase.Prontidao(ctx)
+	})
+	assert.Equal(t, "Instância pronta novamente\n", saida)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-api/controller"
+	"go-api/middleware"
+	"go-api/model"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var sessaoColumns = []string{"id", "usuario_id", "dispositivo", "ip", "criada_em", "ultimo_uso", "encerrada_em"}
+
+func expectSessao(mock sqlmock.Sqlmock, id string, usuarioId int, ultimoUso time.Time, encerradaEm interface{}) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, usuario_id, dispositivo, ip, criada_em, ultimo_uso, encerrada_em FROM sessao WHERE id = ?")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(sessaoColumns).
+			AddRow(id, usuarioId, "curl/8.0", ipTeste, ultimoUso, ultimoUso, encerradaEm))
+}
+
+func setupSessaoRouter() (*gin.Engine, sqlmock.Sqlmock) {
+	db, mock := ConnectMockDB()
+	router := gin.Default()
+
+	authUsecase := newAuthUsecase(db)
+	authController := controller.NewAuthController(authUsecase)
+	router.Use(middleware.Auth(authUsecase, "/auth/login"))
+
+	router.POST("/auth/login", authController.Login)
+	router.GET("/auth/sessions", middleware.RequireSession(), authController.GetSessoes)
+	router.DELETE("/auth/sessions/:sessaoId", middleware.RequireSession(), authController.EncerrarSessao)
+
+	return router, mock
+}
+
+func doSessaoRequest(router *gin.Engine, method, path, sessionId string) *httptest.ResponseRecorder {
+	token, _ := gerarToken(7, model.PapelMembro, sessionId)
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestLoginRegistraSessao(t *testing.T) {
+	router, mock := setupSessaoRouter()
+	hash, _ := senhasTeste.Hash("senha123")
+
+	expectSemTentativas(mock, "login:joao", "ip:"+ipTeste)
+	expectUsuarioByLogin(mock, "joao", hash)
+	expectSemDoisFatores(mock, 1)
+	expectTentativasZeradas(mock, "joao")
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO sessao (id, usuario_id, dispositivo, ip, criada_em, ultimo_uso) VALUES (?, ?, ?, ?, ?, ?)")).
+		WithArgs(sqlmock.AnyArg(), 1, "Firefox/130.0", ipTeste, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectRefreshTokenCreated(mock, 1)
+
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"login":"joao","senha":"senha123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Firefox/130.0")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessoes(t *testing.T) {
+	t.Run("ListaMarcandoASessaoAtual", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+		agora := time.Now()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-1", 7, agora, nil)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, usuario_id, dispositivo, ip, criada_em, ultimo_uso, encerrada_em FROM sessao WHERE usuario_id = ? AND encerrada_em IS NULL ORDER BY ultimo_uso DESC")).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(sessaoColumns).
+				AddRow("sessao-1", 7, "Firefox/130.0", ipTeste, agora, agora, nil).
+				AddRow("sessao-2", 7, "curl/8.0", "198.51.100.7", agora, agora.Add(-time.Hour), nil))
+
+		resp := doSessaoRequest(router, "GET", "/auth/sessions", "sessao-1")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var sessoes []model.Sessao
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &sessoes))
+		assert.Len(t, sessoes, 2)
+		assert.True(t, sessoes[0].Atual)
+		assert.False(t, sessoes[1].Atual)
+		assert.Equal(t, "198.51.100.7", sessoes[1].IP)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RegistraUltimoUso", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-1", 7, time.Now().Add(-time.Hour), nil)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET ultimo_uso = ? WHERE id = ? AND encerrada_em IS NULL")).
+			WithArgs(TempoProximo(0), "sessao-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM sessao WHERE usuario_id = ?")).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(sessaoColumns))
+
+		resp := doSessaoRequest(router, "GET", "/auth/sessions", "sessao-1")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "[]", resp.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("EncerraOutraSessao", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-1", 7, time.Now(), nil)
+		expectSessao(mock, "sessao-2", 7, time.Now(), nil)
+		expectFamiliaRevogada(mock, "sessao-2")
+
+		resp := doSessaoRequest(router, "DELETE", "/auth/sessions/sessao-2", "sessao-1")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SessaoDeOutroUsuario", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-1", 7, time.Now(), nil)
+		expectSessao(mock, "sessao-9", 9, time.Now(), nil)
+
+		resp := doSessaoRequest(router, "DELETE", "/auth/sessions/sessao-9", "sessao-1")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenDeSessaoEncerradaEhRecusado", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		expectSessao(mock, "sessao-2", 7, time.Now(), time.Now())
+
+		resp := doSessaoRequest(router, "GET", "/auth/sessions", "sessao-2")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TokenDeSessaoRemovidaEhRecusado", func(t *testing.T) {
+		router, mock := setupSessaoRouter()
+
+		expectTokenValido(mock, 7, model.PapelMembro)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM sessao WHERE id = ?")).
+			WithArgs("sessao-antiga").
+			WillReturnRows(sqlmock.NewRows(sessaoColumns))
+
+		resp := doSessaoRequest(router, "GET", "/auth/sessions", "sessao-antiga")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
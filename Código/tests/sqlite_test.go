@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"go-api/config"
+	"go-api/db"
+	"go-api/migrations"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ConnectSQLiteDB abre um banco SQLite em memória, exclusivo do teste, com as migrations
+// aplicadas e as tabelas vazias
+func ConnectSQLiteDB(t *testing.T) *sql.DB {
+	cfg := config.Default().Database
+	cfg.Driver = config.DriverSQLite
+	cfg.Path = ":memory:"
+
+	conn, err := db.ConnectDB(context.Background(), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	migrar(t, conn, cfg.Driver)
+	return conn
+}
+
+// migrar aplica todas as migrations do driver ao banco de testes
+func migrar(t *testing.T, conn *sql.DB, driver string) {
+	migrator, err := migrations.New(conn, driver)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+}
+
+func TestSQLiteLogin(t *testing.T) {
+	conn := ConnectSQLiteDB(t)
+	authUsecase := newAuthUsecase(conn)
+
+	hash, _ := senhasTeste.Hash("senha123")
+	usuarios := repository.NewUsuarioRepository(conn)
+	_, err := usuarios.CreateUsuario(model.Usuario{Nome: "João", Login: "joao", Senha: hash, Papel: model.PapelMembro, Status: model.StatusAtivo})
+	require.NoError(t, err)
+
+	// O login grava sessão, refresh token e tentativas, exercitando as colunas de data
+	_, err = authUsecase.Login("joao", "errada", model.Origem{IP: ipTeste})
+	assert.ErrorIs(t, err, usecase.ErrCredenciaisInvalidas)
+	tokens, err := authUsecase.Login("joao", "senha123", model.Origem{IP: ipTeste, UserAgent: "teste"})
+	require.NoError(t, err)
+
+	claims, err := authUsecase.ValidateToken(tokens.Token)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claims.UserId)
+
+	renovados, err := authUsecase.Refresh(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, tokens.RefreshToken, renovados.RefreshToken)
+
+	// Reusar o refresh token antigo encerra a sessão inteira
+	_, err = authUsecase.Refresh(tokens.RefreshToken)
+	assert.Error(t, err)
+	_, err = authUsecase.ValidateToken(renovados.Token)
+	assert.Error(t, err)
+
+	refreshTokens := repository.NewRefreshTokenRepository(conn)
+	removidos, err := refreshTokens.DeleteExpiredRefreshTokens(time.Now().Add(cfgTeste.JWT.RefreshTokenDuration.Duration + time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removidos)
+}
+
+// A limpeza remove as sessões encerradas e as abandonadas, sem derrubar uma sessão recém-criada
+// cujo refresh token ainda não foi gravado
+func TestSQLiteLimpezaSessoes(t *testing.T) {
+	conn := ConnectSQLiteDB(t)
+	usuarioId, err := repository.NewUsuarioRepository(conn).CreateUsuario(model.Usuario{Nome: "João", Login: "joao", Senha: "hash", Papel: model.PapelMembro, Status: model.StatusAtivo})
+	require.NoError(t, err)
+
+	sessoes := repository.NewSessaoRepository(conn)
+	refreshTokens := repository.NewRefreshTokenRepository(conn)
+	now := time.Now()
+	criaSessao := func(id string, criadaEm time.Time, refreshExpiraEm *time.Time) {
+		require.NoError(t, sessoes.CreateSessao(model.Sessao{Id: id, UsuarioId: usuarioId, Dispositivo: "teste", IP: ipTeste, CriadaEm: criadaEm, UltimoUso: criadaEm}))
+		if refreshExpiraEm != nil {
+			_, err := refreshTokens.CreateRefreshToken(model.RefreshToken{TokenHash: id, UsuarioId: usuarioId, Familia: id, ExpiraEm: *refreshExpiraEm})
+			require.NoError(t, err)
+		}
+	}
+	em := func(d time.Duration) *time.Time {
+		tempo := now.Add(d)
+		return &tempo
+	}
+
+	criaSessao("ativa", now.Add(-48*time.Hour), em(time.Hour))
+	criaSessao("abandonada", now.Add(-48*time.Hour), em(-2*time.Hour))
+	criaSessao("expirou-ha-pouco", now.Add(-48*time.Hour), em(-10*time.Minute))
+	criaSessao("nova", now, nil)
+	criaSessao("encerrada", now.Add(-48*time.Hour), em(time.Hour))
+	require.NoError(t, sessoes.EncerrarSessao("encerrada", now))
+
+	removidas, err := sessoes.DeleteSessoesEncerradas(now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removidas)
+
+	for id, existe := range map[string]bool{"ativa": true, "abandonada": false, "expirou-ha-pouco": true, "nova": true, "encerrada": false} {
+		sessao, err := sessoes.GetSessao(id)
+		require.NoError(t, err)
+		assert.Equal(t, existe, sessao != nil, id)
+	}
+}
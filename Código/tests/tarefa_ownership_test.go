@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"go-api/model"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func doTarefaRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestTarefaOwnership(t *testing.T) {
+	membro := model.Caller{UsuarioId: 7, Papel: model.PapelMembro}
+	admin := model.Caller{UsuarioId: 1, Papel: model.PapelAdmin}
+
+	t.Run("TarefaDeOutroUsuarioNaoEncontrada", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupTarefaRouter(db, membro)
+
+		expectTarefaById(mock, 3, "8")
+		resp := doTarefaRequest(router, "GET", "/tarefa/3", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		expectTarefaById(mock, 3, "8")
+		resp = doTarefaRequest(router, "PUT", "/tarefa/3", `{"nome_tarefa":"Invadida"}`)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		expectTarefaById(mock, 3, "8")
+		resp = doTarefaRequest(router, "DELETE", "/tarefa/3", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = doTarefaRequest(router, "GET", "/tarefas/usuario/8", "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AdminAcessaTarefaDeOutroUsuario", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupTarefaRouter(db, admin)
+
+		expectTarefaById(mock, 3, "8")
+		resp := doTarefaRequest(router, "GET", "/tarefa/3", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		expectTarefaById(mock, 3, "8")
+		mock.ExpectPrepare(regexp.QuoteMeta("UPDATE tarefa SET ativo = 'N' WHERE id = ? AND ativo = 'A'")).
+			ExpectExec().
+			WithArgs(3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		resp = doTarefaRequest(router, "DELETE", "/tarefa/3", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, conteudo, usuario_responsavel, finalizado FROM tarefa")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "conteudo", "usuario_responsavel", "finalizado"}).
+				AddRow(3, "Estudar Go", "Estudar interfaces", "8", "N"))
+		resp = doTarefaRequest(router, "GET", "/tarefas", "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("CriacaoUsaOCallerComoResponsavel", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupTarefaRouter(db, membro)
+
+		mock.ExpectExec("INSERT INTO tarefa").
+			WithArgs("Estudar Go", "Estudar interfaces", "7", "N").
+			WillReturnResult(sqlmock.NewResult(5, 1))
+		resp := doTarefaRequest(router, "POST", "/tarefa", `{"nome_tarefa":"Estudar Go","conteudo_tarefa":"Estudar interfaces","finalizado":"N"}`)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Contains(t, resp.Body.String(), `"usuario_responsavel_tarefa":"7"`)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MembroNaoAtribuiTarefaAOutroUsuario", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupTarefaRouter(db, membro)
+
+		resp := doTarefaRequest(router, "POST", "/tarefa", `{"nome_tarefa":"Estudar Go","usuario_responsavel_tarefa":"8"}`)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		expectTarefaById(mock, 3, "7")
+		resp = doTarefaRequest(router, "PUT", "/tarefa/3", `{"nome_tarefa":"Estudar Go","usuario_responsavel_tarefa":"8"}`)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -18,16 +18,18 @@ func TestGetUsuarioById(t *testing.T) {
 
 	repo := repository.NewUsuarioRepository(db)
 	expected := model.Usuario{
-		Id:    1,
-		Nome:  "João",
-		Login: "joao123",
-		Senha: "senha",
+		Id:     1,
+		Nome:   "João",
+		Login:  "joao123",
+		Senha:  "senha",
+		Papel:  "membro",
+		Status: model.StatusAtivo,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha"}).
-		AddRow(expected.Id, expected.Nome, expected.Login, expected.Senha)
+	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+		AddRow(expected.Id, expected.Nome, expected.Login, expected.Senha, expected.Papel, "A")
 
-	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha FROM usuario WHERE id = ?")).
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
 		ExpectQuery().
 		WithArgs(expected.Id).
 		WillReturnRows(rows)
@@ -44,11 +46,11 @@ func TestGetUsuarios(t *testing.T) {
 
 	repo := repository.NewUsuarioRepository(db)
 
-	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha"}).
-		AddRow(1, "João", "joao123", "senha").
-		AddRow(2, "Maria", "maria123", "senha123")
+	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+		AddRow(1, "João", "joao123", "senha", "admin", "A").
+		AddRow(2, "Maria", "maria123", "senha123", "membro", "A")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha FROM usuario")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario")).
 		WillReturnRows(rows)
 
 	result, err := repo.GetUsuarios()
@@ -65,13 +67,15 @@ func TestCreateUsuario(t *testing.T) {
 	repo := repository.NewUsuarioRepository(db)
 
 	input := model.Usuario{
-		Nome:  "Maria",
-		Login: "maria123",
-		Senha: "senha123",
+		Nome:   "Maria",
+		Login:  "maria123",
+		Senha:  "senha123",
+		Papel:  "membro",
+		Status: model.StatusPendente,
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario (nome, login, senha) VALUES (?, ?, ?)")).
-		WithArgs(input.Nome, input.Login, input.Senha).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO usuario (nome, login, senha, papel, ativo) VALUES (?, ?, ?, ?, ?)")).
+		WithArgs(input.Nome, input.Login, input.Senha, input.Papel, "P").
 		WillReturnResult(sqlmock.NewResult(10, 1))
 
 	id, err := repo.CreateUsuario(input)
@@ -79,31 +83,6 @@ func TestCreateUsuario(t *testing.T) {
 	assert.Equal(t, 10, id)
 }
 
-func TestUpdateUsuarioById(t *testing.T) {
-	db, mock, err := sqlmock.New()
-	assert.NoError(t, err)
-	defer db.Close()
-
-	repo := repository.NewUsuarioRepository(db)
-
-	user := &model.Usuario{
-		Nome:  "Atualizado",
-		Login: "atualizado123",
-		Senha: "novaSenha",
-	}
-
-	mock.ExpectPrepare(regexp.QuoteMeta(`
-		UPDATE usuario 
-		SET nome = ?, login = ?, senha = ?
-		WHERE id = ?`)).
-		ExpectExec().
-		WithArgs(user.Nome, user.Login, user.Senha, 1).
-		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	err = repo.UpdateUsuarioById(1, user)
-	assert.NoError(t, err)
-}
-
 func TestSoftDeleteUsuarioById(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -111,7 +90,7 @@ func TestSoftDeleteUsuarioById(t *testing.T) {
 
 	repo := repository.NewUsuarioRepository(db)
 
-	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo = 'A'")).
+	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET ativo = 'I' WHERE id = ? AND ativo <> 'I'")).
 		ExpectExec().
 		WithArgs(5).
 		WillReturnResult(sqlmock.NewResult(0, 1))
@@ -128,16 +107,18 @@ func TestGetUsuarioByLogin(t *testing.T) {
 	repo := repository.NewUsuarioRepository(db)
 
 	expected := model.Usuario{
-		Id:    1,
-		Nome:  "João",
-		Login: "joao123",
-		Senha: "senha",
+		Id:     1,
+		Nome:   "João",
+		Login:  "joao123",
+		Senha:  "senha",
+		Papel:  "membro",
+		Status: model.StatusAtivo,
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha"}).
-		AddRow(expected.Id, expected.Nome, expected.Login, expected.Senha)
+	rows := sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+		AddRow(expected.Id, expected.Nome, expected.Login, expected.Senha, expected.Papel, "A")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha FROM usuario WHERE login = ?")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ?")).
 		WithArgs("joao123").
 		WillReturnRows(rows)
 
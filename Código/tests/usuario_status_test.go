@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"go-api/config"
+	"go-api/controller"
+	"go-api/model"
+	"go-api/repository"
+	"go-api/usecase"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStatusRouter(db *sql.DB) *gin.Engine {
+	router := gin.Default()
+
+	usuarioController := controller.NewUsuarioController(usecase.NewUsuarioUseCase(repository.NewUsuarioRepository(db), newTokenUsecase(db), senhasTeste))
+	router.PUT("/usuario/:usuarioId/status", usuarioController.UpdateStatusById)
+
+	return router
+}
+
+func doStatusRequest(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PUT", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// expectUsuarioComStatus espera a leitura do usuário por id com o código gravado na coluna ativo
+func expectUsuarioComStatus(mock sqlmock.Sqlmock, id int, codigo string) {
+	mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+		ExpectQuery().
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+			AddRow(id, "João", "joao", "hash", model.PapelMembro, codigo))
+}
+
+func expectStatusGravado(mock sqlmock.Sqlmock, id int, codigo string) {
+	mock.ExpectPrepare(regexp.QuoteMeta("UPDATE usuario SET ativo = ? WHERE id = ?")).
+		ExpectExec().
+		WithArgs(codigo, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestUpdateStatusUsuario(t *testing.T) {
+	t.Run("SuspensaoEncerraSessoes", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupStatusRouter(db)
+
+		expectUsuarioComStatus(mock, 7, "A")
+		expectStatusGravado(mock, 7, "S")
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET revogado = 'S' WHERE usuario_id = ?")).
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE sessao SET encerrada_em = ? WHERE usuario_id = ? AND encerrada_em IS NULL")).
+			WithArgs(sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		resp := doStatusRequest(router, "/usuario/7/status", `{"status_usuario":"suspenso"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AtivaContaPendente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupStatusRouter(db)
+
+		expectUsuarioComStatus(mock, 7, "P")
+		expectStatusGravado(mock, 7, "A")
+
+		resp := doStatusRequest(router, "/usuario/7/status", `{"status_usuario":"ativo"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TransicaoInvalida", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupStatusRouter(db)
+
+		// Uma conta ativa não volta a ficar pendente
+		expectUsuarioComStatus(mock, 7, "A")
+
+		resp := doStatusRequest(router, "/usuario/7/status", `{"status_usuario":"pendente"}`)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("StatusInvalido", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupStatusRouter(db)
+
+		resp := doStatusRequest(router, "/usuario/7/status", `{"status_usuario":"banido"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UsuarioInexistente", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		router := setupStatusRouter(db)
+
+		mock.ExpectPrepare(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE id = ?")).
+			ExpectQuery().
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}))
+
+		resp := doStatusRequest(router, "/usuario/9/status", `{"status_usuario":"suspenso"}`)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLoginContaInativa(t *testing.T) {
+	chaves := []string{"login:joao", "ip:" + ipTeste}
+
+	expectUsuarioComCodigo := func(mock sqlmock.Sqlmock, hash, codigo string) {
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT id, nome, login, senha, papel, ativo FROM usuario WHERE login = ? AND ativo <> 'I'")).
+			WithArgs("joao").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "nome", "login", "senha", "papel", "ativo"}).
+				AddRow(1, "João", "joao", hash, model.PapelMembro, codigo))
+	}
+
+	t.Run("ContaSuspensa", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+		hash, _ := senhasTeste.Hash("senha123")
+
+		expectSemTentativas(mock, chaves...)
+		expectUsuarioComCodigo(mock, hash, "S")
+
+		resp := doLogin(router, "joao", "senha123")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), usecase.ErrContaSuspensa.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ContaPendente", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+		hash, _ := senhasTeste.Hash("senha123")
+
+		expectSemTentativas(mock, chaves...)
+		expectUsuarioComCodigo(mock, hash, "P")
+
+		resp := doLogin(router, "joao", "senha123")
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), usecase.ErrContaPendente.Error())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("SenhaErradaNaoRevelaStatus", func(t *testing.T) {
+		router, mock := setupLoginRouter()
+		hash, _ := senhasTeste.Hash("senha123")
+
+		expectSemTentativas(mock, chaves...)
+		expectUsuarioComCodigo(mock, hash, "S")
+		expectFalhaRegistrada(mock, "login:joao", 1)
+		expectFalhaRegistrada(mock, "ip:"+ipTeste, 1)
+
+		resp := doLogin(router, "joao", "errada")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTokensDeContaInativa(t *testing.T) {
+	t.Run("RefreshRecusado", func(t *testing.T) {
+		db, mock := ConnectMockDB()
+		authUsecase := newAuthUsecase(db)
+
+		expectRefreshTokenByHash(mock, "refresh-1", sqlmock.NewRows(refreshTokenColumns).
+			AddRow(10, config.HashOpaqueToken("refresh-1"), 7, "familia-1", time.Now().Add(time.Hour), nil, "N"))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE refresh_token SET usado_em = ? WHERE id = ? AND usado_em IS NULL AND revogado = 'N'")).
+			WithArgs(sqlmock.AnyArg(), 10).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUsuarioComStatus(mock, 7, "S")
+
+		_, err := authUsecase.Refresh("refresh-1")
+		assert.ErrorIs(t, err, usecase.ErrRefreshTokenInvalido)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ApiKeyDeUsuarioSuspenso", func(t *testing.T) {
+		router, mock := setupApiKeyRouter()
+		key, _, _ := config.GenerateApiKey()
+
+		expectApiKeyByHash(mock, key, "tarefas:read", nil, nil, "N")
+		expectUsuarioComStatus(mock, 7, "S")
+
+		resp := doApiKeyRequest(router, "GET", "/tarefas", map[string]string{"X-API-Key": key}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
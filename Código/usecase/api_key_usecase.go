@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"go-api/config"
+	"go-api/model"
+	"go-api/repository"
+	"time"
+)
+
+var (
+	ErrApiKeyInvalida   = errors.New("API key inválida, revogada ou expirada")
+	ErrEscopoInvalido   = errors.New("informe ao menos um escopo válido: tarefas:read, tarefas:write, usuarios:read ou usuarios:write")
+	ErrExpiracaoPassada = errors.New("a data de expiração precisa estar no futuro")
+)
+
+// Intervalo mínimo entre as gravações de último uso de uma mesma chave, para não gerar uma
+// escrita no banco a cada requisição
+const intervaloUltimoUso = time.Minute
+
+type ApiKeyUsecase struct {
+	repository        repository.ApiKeyRepository
+	usuarioRepository repository.UsuarioStore
+}
+
+func NewApiKeyUsecase(repo repository.ApiKeyRepository, usuarioRepo repository.UsuarioStore) *ApiKeyUsecase {
+	return &ApiKeyUsecase{repository: repo, usuarioRepository: usuarioRepo}
+}
+
+// CreateApiKey cria uma chave para quem fez a requisição. A chave só é retornada aqui.
+func (au *ApiKeyUsecase) CreateApiKey(caller model.Caller, request model.ApiKeyRequest) (*model.ApiKeyCreatedResponse, error) {
+	escopos, err := normalizeEscopos(request.Escopos)
+	if err != nil {
+		return nil, err
+	}
+	if request.ExpiraEm != nil && !request.ExpiraEm.After(time.Now()) {
+		return nil, ErrExpiracaoPassada
+	}
+
+	key, prefixo, err := config.GenerateApiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := model.ApiKey{
+		UsuarioId: caller.UsuarioId,
+		Nome:      request.Nome,
+		Prefixo:   prefixo,
+		KeyHash:   config.HashOpaqueToken(key),
+		Escopos:   escopos,
+		ExpiraEm:  request.ExpiraEm,
+		CriadaEm:  time.Now(),
+	}
+	apiKey.Id, err = au.repository.CreateApiKey(apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ApiKeyCreatedResponse{ApiKey: apiKey, Key: key}, nil
+}
+
+func (au *ApiKeyUsecase) GetApiKeys(caller model.Caller) ([]model.ApiKey, error) {
+	return au.repository.GetApiKeysByUsuarioId(caller.UsuarioId)
+}
+
+// RevokeApiKey revoga uma chave de quem fez a requisição. Chaves de outros usuários
+// resultam em sql.ErrNoRows, como se não existissem.
+func (au *ApiKeyUsecase) RevokeApiKey(caller model.Caller, id int) error {
+	return au.repository.RevokeApiKey(id, caller.UsuarioId)
+}
+
+// Authenticate identifica o dono da chave. O papel e o status são lidos do usuário a cada
+// requisição, de modo que mudanças de papel e suspensões valem imediatamente para as chaves já emitidas.
+func (au *ApiKeyUsecase) Authenticate(key string) (*model.Caller, error) {
+	apiKey, err := au.repository.GetApiKeyByHash(config.HashOpaqueToken(key))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if apiKey == nil || apiKey.Revogada == "S" || (apiKey.ExpiraEm != nil && !apiKey.ExpiraEm.After(now)) {
+		return nil, ErrApiKeyInvalida
+	}
+
+	usuario, err := au.usuarioRepository.GetUsuarioById(apiKey.UsuarioId)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil || !usuario.IsAtivo() {
+		return nil, ErrApiKeyInvalida
+	}
+
+	if apiKey.UltimoUso == nil || now.Sub(*apiKey.UltimoUso) >= intervaloUltimoUso {
+		if err := au.repository.UpdateUltimoUso(apiKey.Id, now); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	return &model.Caller{
+		UsuarioId: usuario.Id,
+		Papel:     usuario.Papel,
+		ApiKeyId:  apiKey.Id,
+		Escopos:   apiKey.Escopos,
+	}, nil
+}
+
+func normalizeEscopos(escopos []string) ([]string, error) {
+	vistos := make(map[string]bool, len(escopos))
+	normalizados := make([]string, 0, len(escopos))
+	for _, escopo := range escopos {
+		if !model.IsEscopoValido(escopo) {
+			return nil, ErrEscopoInvalido
+		}
+		if !vistos[escopo] {
+			vistos[escopo] = true
+			normalizados = append(normalizados, escopo)
+		}
+	}
+	if len(normalizados) == 0 {
+		return nil, ErrEscopoInvalido
+	}
+	return normalizados, nil
+}
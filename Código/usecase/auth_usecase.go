@@ -1,27 +1,353 @@
 package usecase
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"go-api/config"
+	"go-api/model"
 	"go-api/repository"
+	"strings"
 )
 
+var (
+	ErrCredenciaisInvalidas = errors.New("login ou senha inválidos")
+	ErrSenhaAtualIncorreta  = errors.New("senha atual incorreta")
+	ErrLoginEmUso           = errors.New("login já está em uso")
+	ErrPerfilInvalido       = errors.New("nome e login não podem ser vazios")
+	ErrContaPendente        = errors.New("a conta ainda não foi ativada")
+	ErrContaSuspensa        = errors.New("a conta está suspensa")
+)
+
+// contaInativa retorna o erro de login correspondente ao status de uma conta que não está ativa.
+// Contas desativadas não chegam aqui, pois o login não as encontra.
+func contaInativa(usuario *model.Usuario) error {
+	switch usuario.Status {
+	case model.StatusAtivo:
+		return nil
+	case model.StatusPendente:
+		return ErrContaPendente
+	case model.StatusSuspenso:
+		return ErrContaSuspensa
+	default:
+		return ErrCredenciaisInvalidas
+	}
+}
+
 type AuthUsecase struct {
-	UsuarioRepo repository.UsuarioRepository
+	UsuarioRepo repository.UsuarioStore
+	Tokens      *TokenUsecase
+	Tentativas  *TentativaLoginUsecase
+	DoisFatores *DoisFatoresUsecase
+	ApiKeys     *ApiKeyUsecase
+	Senhas      config.PasswordConfig
 }
 
-func NewAuthUsecase(repo repository.UsuarioRepository) *AuthUsecase {
-	return &AuthUsecase{UsuarioRepo: repo}
+func NewAuthUsecase(repo repository.UsuarioStore, tokens *TokenUsecase, tentativas *TentativaLoginUsecase, doisFatores *DoisFatoresUsecase, apiKeys *ApiKeyUsecase, senhas config.PasswordConfig) *AuthUsecase {
+	return &AuthUsecase{UsuarioRepo: repo, Tokens: tokens, Tentativas: tentativas, DoisFatores: doisFatores, ApiKeys: apiKeys, Senhas: senhas}
 }
 
-func (uc *AuthUsecase) Login(login, senha string) (string, error) {
+// Login autentica o usuário, registra uma nova sessão e emite o par de tokens. O IP da origem
+// identifica o cliente para a contagem de falhas e, junto com o user-agent, é gravado na
+// sessão; enquanto o login ou o IP estiver bloqueado, retorna um *BloqueioError sem
+// sequer conferir a senha. Usuários com segundo fator recebem apenas um token de desafio,
+// trocado pelos tokens definitivos em VerifyDoisFatores.
+func (uc *AuthUsecase) Login(login, senha string, origem model.Origem) (*model.TokenResponse, error) {
+	if err := uc.Tentativas.Verificar(login, origem.IP); err != nil {
+		return nil, err
+	}
+
 	usuario, err := uc.UsuarioRepo.GetUsuarioByLogin(login)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if usuario == nil {
+		uc.Senhas.DummyCheck(senha)
+		return nil, uc.falhaLogin(login, origem.IP)
+	}
+
+	ok, needsRehash := uc.Senhas.Check(usuario.Senha, senha)
+	if !ok {
+		return nil, uc.falhaLogin(login, origem.IP)
+	}
+	// O status só é revelado a quem conhece a senha
+	if err := contaInativa(usuario); err != nil {
+		return nil, err
+	}
+
+	// Regrava senhas legadas em texto puro ou com custo desatualizado
+	if needsRehash {
+		if err := uc.rehashSenha(usuario.Id, senha); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	doisFatores, err := uc.DoisFatores.Ativo(usuario.Id)
+	if err != nil {
+		return nil, err
 	}
-	if usuario == nil || usuario.Senha != senha {
-		return "", errors.New("login ou senha inválidos")
+	if doisFatores {
+		// As falhas só são zeradas depois do código, senão quem sabe a senha poderia
+		// alternar entre senha e códigos para tentar códigos indefinidamente
+		challenge, err := uc.Tokens.GenerateChallengeToken(usuario.Id)
+		if err != nil {
+			return nil, err
+		}
+		return &model.TokenResponse{
+			DoisFatores:    true,
+			ChallengeToken: challenge,
+			ExpiresIn:      int64(config.ChallengeTokenDuration.Seconds()),
+		}, nil
 	}
 
-	return config.GenerateToken(usuario.Id)
+	if err := uc.Tentativas.RegistrarSucesso(login); err != nil {
+		fmt.Println(err)
+	}
+	return uc.Tokens.StartSession(usuario, origem)
+}
+
+// IssueToken abre uma sessão para o usuário do login sem conferir a senha nem o segundo
+// fator, para scripts e administradores com acesso ao servidor. A conta precisa estar ativa.
+func (uc *AuthUsecase) IssueToken(login string, origem model.Origem) (*model.TokenResponse, error) {
+	usuario, err := uc.UsuarioRepo.GetUsuarioByLogin(login)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil {
+		return nil, ErrUsuarioInexistente
+	}
+	if err := contaInativa(usuario); err != nil {
+		return nil, err
+	}
+	return uc.Tokens.StartSession(usuario, origem)
+}
+
+// VerifyDoisFatores conclui o login de quem tem segundo fator. Códigos errados contam como
+// falhas de login e o token de desafio só pode ser usado uma vez.
+func (uc *AuthUsecase) VerifyDoisFatores(challengeToken, codigo string, origem model.Origem) (*model.TokenResponse, error) {
+	claims, err := uc.Tokens.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, ErrChallengeTokenInvalido
+	}
+	revoked, err := uc.Tokens.IsTokenRevoked(claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrChallengeTokenInvalido
+	}
+
+	usuario, err := uc.UsuarioRepo.GetUsuarioById(claims.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil || usuario.Status == model.StatusDesativado {
+		return nil, ErrChallengeTokenInvalido
+	}
+	if err := contaInativa(usuario); err != nil {
+		return nil, err
+	}
+
+	if err := uc.Tentativas.Verificar(usuario.Login, origem.IP); err != nil {
+		return nil, err
+	}
+
+	ok, err := uc.DoisFatores.Verificar(usuario.Id, codigo)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := uc.Tentativas.RegistrarFalha(usuario.Login, origem.IP); err != nil {
+			fmt.Println(err)
+		}
+		return nil, ErrCodigoDoisFatores
+	}
+
+	if err := uc.Tokens.RevokeToken(claims); err != nil {
+		return nil, err
+	}
+	if err := uc.Tentativas.RegistrarSucesso(usuario.Login); err != nil {
+		fmt.Println(err)
+	}
+	return uc.Tokens.StartSession(usuario, origem)
+}
+
+// Refresh troca o refresh token por um novo par, relendo o usuário para que o token de
+// acesso reflita o papel atual
+func (uc *AuthUsecase) Refresh(refreshToken string) (*model.TokenResponse, error) {
+	stored, err := uc.Tokens.ConsumeRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario, err := uc.UsuarioRepo.GetUsuarioById(stored.UsuarioId)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil || !usuario.IsAtivo() {
+		return nil, ErrRefreshTokenInvalido
+	}
+
+	return uc.Tokens.IssueTokens(usuario, stored.Familia)
+}
+
+// Logout revoga o token de acesso e encerra a família de refresh tokens da sessão
+func (uc *AuthUsecase) Logout(claims *config.Claims) error {
+	if err := uc.Tokens.RevokeToken(claims); err != nil {
+		return err
+	}
+	if claims.SessionId == "" {
+		return nil
+	}
+	return uc.Tokens.RevokeFamilia(claims.SessionId)
+}
+
+// ValidateToken confere assinatura e expiração do token e recusa tokens revogados no logout,
+// de usuários que não estão mais ativos ou pertencentes a sessões encerradas. A situação do
+// usuário é lida a cada requisição, como nas API keys: mudar o status encerra as sessões, mas
+// uma alteração feita direto no banco não passaria por isso.
+func (uc *AuthUsecase) ValidateToken(token string) (*config.Claims, error) {
+	claims, err := uc.Tokens.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := uc.Tokens.IsTokenRevoked(claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, config.ErrTokenInvalido
+	}
+
+	usuario, err := uc.UsuarioRepo.GetUsuarioById(claims.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil || !usuario.IsAtivo() {
+		return nil, config.ErrTokenInvalido
+	}
+
+	if claims.SessionId != "" {
+		ativa, err := uc.Tokens.SessaoAtiva(claims.SessionId)
+		if err != nil {
+			return nil, err
+		}
+		if !ativa {
+			return nil, config.ErrTokenInvalido
+		}
+	}
+
+	return claims, nil
+}
+
+// DesbloquearUsuario remove o bloqueio por falhas de login do usuário
+func (uc *AuthUsecase) DesbloquearUsuario(id_usuario int) error {
+	usuario, err := uc.UsuarioRepo.GetUsuarioById(id_usuario)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return sql.ErrNoRows
+	}
+
+	_, err = uc.Tentativas.Desbloquear(usuario.Login)
+	return err
+}
+
+// Me retorna o usuário autenticado
+func (uc *AuthUsecase) Me(id_usuario int) (*model.Usuario, error) {
+	usuario, err := uc.UsuarioRepo.GetUsuarioById(id_usuario)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil {
+		return nil, sql.ErrNoRows
+	}
+	return usuario, nil
+}
+
+// UpdatePerfil altera nome e login do próprio usuário, sem tocar na senha nem no papel
+func (uc *AuthUsecase) UpdatePerfil(id_usuario int, request model.PerfilRequest) (*model.Usuario, error) {
+	usuario, err := uc.Me(id_usuario)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Nome != nil {
+		usuario.Nome = strings.TrimSpace(*request.Nome)
+	}
+	if request.Login != nil {
+		usuario.Login = strings.TrimSpace(*request.Login)
+	}
+	if usuario.Nome == "" || usuario.Login == "" {
+		return nil, ErrPerfilInvalido
+	}
+
+	existente, err := uc.UsuarioRepo.GetUsuarioByLogin(usuario.Login)
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil && existente.Id != usuario.Id {
+		return nil, ErrLoginEmUso
+	}
+
+	// A consulta acima não impede que outra requisição ocupe o login antes da gravação
+	err = uc.UsuarioRepo.UpdatePerfilById(usuario.Id, usuario.Nome, usuario.Login)
+	if errors.Is(err, repository.ErrRegistroDuplicado) {
+		return nil, ErrLoginEmUso
+	}
+	if err != nil {
+		return nil, err
+	}
+	return usuario, nil
+}
+
+// ChangePassword troca a senha de quem conhece a senha atual. Senhas atuais erradas contam
+// como falhas de login, e as demais sessões do usuário são encerradas; a sessão da
+// requisição, identificada pelas claims, continua válida.
+func (uc *AuthUsecase) ChangePassword(claims *config.Claims, senhaAtual, novaSenha, ip string) error {
+	usuario, err := uc.Me(claims.UserId)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.Tentativas.Verificar(usuario.Login, ip); err != nil {
+		return err
+	}
+
+	if ok, _ := uc.Senhas.Check(usuario.Senha, senhaAtual); !ok {
+		if err := uc.Tentativas.RegistrarFalha(usuario.Login, ip); err != nil {
+			fmt.Println(err)
+		}
+		return ErrSenhaAtualIncorreta
+	}
+
+	hash, err := uc.Senhas.Hash(novaSenha)
+	if err != nil {
+		return err
+	}
+	if err := uc.UsuarioRepo.UpdateSenhaById(usuario.Id, hash); err != nil {
+		return err
+	}
+
+	if err := uc.Tentativas.RegistrarSucesso(usuario.Login); err != nil {
+		fmt.Println(err)
+	}
+	return uc.Tokens.RevokeOutrasSessoes(usuario.Id, claims.SessionId)
+}
+
+func (uc *AuthUsecase) falhaLogin(login, ip string) error {
+	if err := uc.Tentativas.RegistrarFalha(login, ip); err != nil {
+		fmt.Println(err)
+	}
+	return ErrCredenciaisInvalidas
+}
+
+func (uc *AuthUsecase) rehashSenha(id_usuario int, senha string) error {
+	hash, err := uc.Senhas.Hash(senha)
+	if err != nil {
+		return err
+	}
+	return uc.UsuarioRepo.UpdateSenhaById(id_usuario, hash)
 }
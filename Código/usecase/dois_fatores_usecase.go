@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"database/sql"
+	"errors"
+	"go-api/config"
+	"go-api/model"
+	"go-api/repository"
+	"time"
+)
+
+// Quantidade de códigos de recuperação gerados na confirmação do segundo fator
+const quantidadeCodigosRecuperacao = 10
+
+var (
+	ErrDoisFatoresJaAtivo     = errors.New("o segundo fator já está ativo para este usuário")
+	ErrDoisFatoresNaoIniciado = errors.New("inicie o cadastro do segundo fator antes de confirmá-lo")
+	ErrCodigoDoisFatores      = errors.New("código do segundo fator inválido")
+	ErrChallengeTokenInvalido = errors.New("token de desafio inválido ou expirado")
+)
+
+// DoisFatoresUsecase cadastra e confere o segundo fator TOTP dos usuários
+type DoisFatoresUsecase struct {
+	repository        repository.DoisFatoresRepository
+	usuarioRepository repository.UsuarioStore
+}
+
+func NewDoisFatoresUsecase(repo repository.DoisFatoresRepository, usuarioRepo repository.UsuarioStore) *DoisFatoresUsecase {
+	return &DoisFatoresUsecase{repository: repo, usuarioRepository: usuarioRepo}
+}
+
+// Ativo indica se o usuário já confirmou o segundo fator
+func (du *DoisFatoresUsecase) Ativo(usuarioId int) (bool, error) {
+	doisFatores, err := du.repository.GetDoisFatores(usuarioId)
+	if err != nil {
+		return false, err
+	}
+	return doisFatores != nil && doisFatores.Confirmado == "S", nil
+}
+
+// Enroll gera um novo segredo para o usuário. O segundo fator só passa a ser exigido no
+// login depois que Confirm recebe um código válido gerado a partir dele.
+func (du *DoisFatoresUsecase) Enroll(usuarioId int) (*model.DoisFatoresEnrollResponse, error) {
+	ativo, err := du.Ativo(usuarioId)
+	if err != nil {
+		return nil, err
+	}
+	if ativo {
+		return nil, ErrDoisFatoresJaAtivo
+	}
+
+	usuario, err := du.usuarioRepository.GetUsuarioById(usuarioId)
+	if err != nil {
+		return nil, err
+	}
+	if usuario == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	segredo, err := config.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := du.repository.SaveSegredo(usuarioId, segredo); err != nil {
+		return nil, err
+	}
+
+	return &model.DoisFatoresEnrollResponse{
+		Segredo:    segredo,
+		OtpauthURI: config.TOTPURI(usuario.Login, segredo),
+	}, nil
+}
+
+// Confirm ativa o segundo fator com o primeiro código do autenticador e retorna os códigos
+// de recuperação, que não poderão ser consultados novamente
+func (du *DoisFatoresUsecase) Confirm(usuarioId int, codigo string) ([]string, error) {
+	doisFatores, err := du.repository.GetDoisFatores(usuarioId)
+	if err != nil {
+		return nil, err
+	}
+	if doisFatores == nil {
+		return nil, ErrDoisFatoresNaoIniciado
+	}
+	if doisFatores.Confirmado == "S" {
+		return nil, ErrDoisFatoresJaAtivo
+	}
+
+	ok, err := du.useTOTP(doisFatores, codigo)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCodigoDoisFatores
+	}
+
+	codigos := make([]string, quantidadeCodigosRecuperacao)
+	hashes := make([]string, quantidadeCodigosRecuperacao)
+	for i := range codigos {
+		codigo, err := config.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codigos[i] = codigo
+		hashes[i] = config.HashOpaqueToken(config.NormalizeRecoveryCode(codigo))
+	}
+	if err := du.repository.ReplaceCodigosRecuperacao(usuarioId, hashes); err != nil {
+		return nil, err
+	}
+
+	return codigos, nil
+}
+
+// Verificar confere o código do autenticador ou, na falta dele, um código de recuperação,
+// que é consumido. Cada código só é aceito uma vez.
+func (du *DoisFatoresUsecase) Verificar(usuarioId int, codigo string) (bool, error) {
+	doisFatores, err := du.repository.GetDoisFatores(usuarioId)
+	if err != nil {
+		return false, err
+	}
+	if doisFatores == nil || doisFatores.Confirmado != "S" {
+		return false, nil
+	}
+
+	ok, err := du.useTOTP(doisFatores, codigo)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	hash := config.HashOpaqueToken(config.NormalizeRecoveryCode(codigo))
+	return du.repository.UseCodigoRecuperacao(usuarioId, hash, time.Now())
+}
+
+func (du *DoisFatoresUsecase) useTOTP(doisFatores *model.DoisFatores, codigo string) (bool, error) {
+	passo, ok := config.ValidateTOTP(doisFatores.Segredo, codigo, time.Now())
+	if !ok || passo <= doisFatores.UltimoPasso {
+		return false, nil
+	}
+	return du.repository.UseCodigo(doisFatores.UsuarioId, passo)
+}
@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-api/config"
+	"go-api/model"
+	"go-api/oidc"
+	"go-api/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOidcStateInvalido = errors.New("state do login OIDC inválido ou expirado")
+	ErrOidcLoginFalhou   = errors.New("não foi possível concluir o login com o provedor de identidade")
+	ErrOidcLoginEmUso    = errors.New("já existe um usuário local com o login informado pelo provedor de identidade")
+	ErrOidcMuitosLogins  = errors.New("muitos logins OIDC em andamento, tente novamente mais tarde")
+)
+
+const (
+	// Tempo que o usuário tem para concluir o login no provedor de identidade
+	oidcPendenteDuracao = 10 * time.Minute
+	// Limite padrão de logins iniciados e ainda não concluídos
+	oidcMaxPendentes = 10000
+)
+
+type oidcPendente struct {
+	verifier string
+	nonce    string
+	expiraEm time.Time
+}
+
+// OidcUsecase implementa o login pelo fluxo authorization code do OpenID Connect. O state, o
+// nonce e o verificador PKCE de cada login iniciado ficam em memória até o callback, que os
+// consome uma única vez. Por isso um login iniciado não sobrevive a um reinício e o callback
+// precisa chegar à mesma instância que atendeu /auth/oidc/login; com várias instâncias, o
+// balanceador de carga deve manter o usuário na mesma instância durante o login.
+//
+// Como /auth/oidc/login é público, os logins pendentes são limitados a MaxPendentes. Ao atingir
+// o limite, os expirados são descartados e, se ainda não houver espaço, o login é recusado.
+type OidcUsecase struct {
+	provider          *oidc.Provider
+	usuarioRepository repository.UsuarioStore
+	repository        repository.IdentidadeExternaRepository
+	tokens            *TokenUsecase
+	senhas            config.PasswordConfig
+
+	MaxPendentes int
+
+	mu        sync.Mutex
+	pendentes map[string]oidcPendente
+}
+
+func NewOidcUsecase(provider *oidc.Provider, usuarioRepo repository.UsuarioStore, repo repository.IdentidadeExternaRepository, tokens *TokenUsecase, senhas config.PasswordConfig) *OidcUsecase {
+	return &OidcUsecase{
+		provider:          provider,
+		usuarioRepository: usuarioRepo,
+		repository:        repo,
+		tokens:            tokens,
+		senhas:            senhas,
+		MaxPendentes:      oidcMaxPendentes,
+		pendentes:         make(map[string]oidcPendente),
+	}
+}
+
+// Start inicia um login e retorna a URL do provedor para onde o usuário deve ser redirecionado
+func (ou *OidcUsecase) Start(ctx context.Context) (string, error) {
+	state, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := ou.provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	ou.mu.Lock()
+	defer ou.mu.Unlock()
+	if len(ou.pendentes) >= ou.MaxPendentes {
+		for s, p := range ou.pendentes {
+			if now.After(p.expiraEm) {
+				delete(ou.pendentes, s)
+			}
+		}
+		if len(ou.pendentes) >= ou.MaxPendentes {
+			return "", ErrOidcMuitosLogins
+		}
+	}
+	ou.pendentes[state] = oidcPendente{verifier: verifier, nonce: nonce, expiraEm: now.Add(oidcPendenteDuracao)}
+
+	return authURL, nil
+}
+
+// Callback troca o código recebido do provedor pelo ID token, encontra ou provisiona o
+// usuário local correspondente e emite os tokens desta API
+func (ou *OidcUsecase) Callback(ctx context.Context, state, code string, origem model.Origem) (*model.TokenResponse, error) {
+	ou.mu.Lock()
+	pendente, ok := ou.pendentes[state]
+	delete(ou.pendentes, state)
+	ou.mu.Unlock()
+
+	if !ok || time.Now().After(pendente.expiraEm) {
+		return nil, ErrOidcStateInvalido
+	}
+
+	claims, err := ou.provider.Exchange(ctx, code, pendente.verifier, pendente.nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOidcLoginFalhou, err)
+	}
+
+	usuario, err := ou.usuarioDaIdentidade(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return ou.tokens.StartSession(usuario, origem)
+}
+
+// usuarioDaIdentidade retorna o usuário ligado ao sujeito do provedor, criando-o no primeiro login.
+// Um usuário local com o mesmo login nunca é ligado automaticamente: quem controla a conta no
+// provedor não necessariamente é o dono da conta local.
+func (ou *OidcUsecase) usuarioDaIdentidade(claims *oidc.IDTokenClaims) (*model.Usuario, error) {
+	issuer := ou.provider.Issuer()
+
+	identidade, err := ou.repository.GetIdentidade(issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identidade != nil {
+		usuario, err := ou.usuarioRepository.GetUsuarioById(identidade.UsuarioId)
+		if err != nil {
+			return nil, err
+		}
+		if usuario == nil || usuario.Status == model.StatusDesativado {
+			return nil, ErrOidcLoginFalhou
+		}
+		if err := contaInativa(usuario); err != nil {
+			return nil, err
+		}
+		return usuario, nil
+	}
+
+	login := oidcLogin(claims)
+	existente, err := ou.usuarioRepository.GetUsuarioByLogin(login)
+	if err != nil {
+		return nil, err
+	}
+	if existente != nil {
+		return nil, ErrOidcLoginEmUso
+	}
+
+	// A conta provisionada só entra pelo provedor; a senha aleatória nunca é revelada
+	senha, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := ou.senhas.Hash(senha)
+	if err != nil {
+		return nil, err
+	}
+
+	usuario := model.Usuario{
+		Nome:   claims.Name,
+		Login:  login,
+		Senha:  hash,
+		Papel:  model.PapelMembro,
+		Status: model.StatusAtivo,
+	}
+	if usuario.Nome == "" {
+		usuario.Nome = login
+	}
+
+	// Um primeiro login simultâneo do mesmo sujeito pode ocupar o login entre a consulta
+	// acima e a gravação
+	usuario.Id, err = ou.usuarioRepository.CreateUsuario(usuario)
+	if errors.Is(err, repository.ErrRegistroDuplicado) {
+		return nil, ErrOidcLoginEmUso
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = ou.repository.CreateIdentidade(model.IdentidadeExterna{
+		UsuarioId: usuario.Id,
+		Issuer:    issuer,
+		Subject:   claims.Subject,
+		CriadaEm:  time.Now(),
+	})
+	if err != nil {
+		// Sem a identidade, o usuário criado ocuparia o login e recusaria os próximos logins
+		// do mesmo sujeito com ErrOidcLoginEmUso
+		if errDelete := ou.usuarioRepository.DeleteUsuarioById(usuario.Id); errDelete != nil {
+			return nil, errors.Join(err, errDelete)
+		}
+		if errors.Is(err, repository.ErrRegistroDuplicado) {
+			return nil, ErrOidcLoginEmUso
+		}
+		return nil, err
+	}
+
+	return &usuario, nil
+}
+
+// oidcLogin escolhe o login do usuário provisionado: o nome de usuário do provedor, o e-mail
+// verificado ou, na falta de ambos, um login derivado do sujeito
+func oidcLogin(claims *oidc.IDTokenClaims) string {
+	if claims.PreferredUsername != "" {
+		return claims.PreferredUsername
+	}
+	if claims.Email != "" && claims.EmailVerified {
+		return strings.ToLower(claims.Email)
+	}
+	return "oidc-" + claims.Subject
+}
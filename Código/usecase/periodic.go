@@ -0,0 +1,24 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// runPeriodically executa fn a cada intervalo até o contexto ser cancelado, registrando os erros
+func runPeriodically(ctx context.Context, interval time.Duration, fn func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(); err != nil {
+				fmt.Println(err)
+			}
+		}
+	}
+}
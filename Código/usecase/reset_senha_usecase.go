@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-api/config"
+	"go-api/model"
+	"go-api/notification"
+	"go-api/repository"
+	"net/url"
+	"time"
+)
+
+var ErrResetTokenInvalido = errors.New("token de redefinição de senha inválido ou expirado")
+
+// Intervalo mínimo entre dois tokens de redefinição para o mesmo usuário. Como /auth/forgot-password
+// não exige autenticação, sem ele qualquer um que saiba o login invalidaria o link do usuário e
+// encheria sua caixa de notificações.
+const resetSenhaIntervalo = 5 * time.Minute
+
+// ResetSenhaUsecase implementa a recuperação de senha com tokens de uso único. Apenas o hash
+// do token é gravado; o token em si só existe na notificação enviada ao usuário.
+type ResetSenhaUsecase struct {
+	usuarioRepository repository.UsuarioStore
+	repository        repository.ResetSenhaRepository
+	tokens            *TokenUsecase
+	tentativas        *TentativaLoginUsecase
+	sender            notification.Sender
+	config            config.ResetSenhaConfig
+	senhas            config.PasswordConfig
+}
+
+func NewResetSenhaUsecase(usuarioRepo repository.UsuarioStore, repo repository.ResetSenhaRepository, tokens *TokenUsecase, tentativas *TentativaLoginUsecase, sender notification.Sender, cfg config.ResetSenhaConfig, senhas config.PasswordConfig) *ResetSenhaUsecase {
+	return &ResetSenhaUsecase{
+		usuarioRepository: usuarioRepo,
+		repository:        repo,
+		tokens:            tokens,
+		tentativas:        tentativas,
+		sender:            sender,
+		config:            cfg,
+		senhas:            senhas,
+	}
+}
+
+// ForgotPassword envia um token de redefinição ao usuário do login informado. Logins
+// inexistentes não geram erro, para que a resposta não revele quais logins existem; pelo mesmo
+// motivo, um pedido feito antes de resetSenhaIntervalo desde o último token é ignorado em silêncio.
+func (ru *ResetSenhaUsecase) ForgotPassword(login string) error {
+	usuario, err := ru.usuarioRepository.GetUsuarioByLogin(login)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return nil
+	}
+
+	// Os tokens valem por config.Duracao: um pendente que expira depois de agora + Duracao -
+	// intervalo foi emitido há menos de resetSenhaIntervalo
+	recente, err := ru.repository.ExisteResetSenhaPendente(usuario.Id, time.Now().Add(ru.config.Duracao.Duration-resetSenhaIntervalo))
+	if err != nil {
+		return err
+	}
+	if recente {
+		return nil
+	}
+
+	// Apenas o token mais recente vale
+	if err := ru.repository.DeleteResetSenhasPendentes(usuario.Id); err != nil {
+		return err
+	}
+
+	token, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = ru.repository.CreateResetSenha(model.ResetSenha{
+		TokenHash: config.HashOpaqueToken(token),
+		UsuarioId: usuario.Id,
+		ExpiraEm:  time.Now().Add(ru.config.Duracao.Duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	return ru.sender.Send(notification.Message{
+		Destinatario: usuario.Login,
+		Assunto:      "Redefinição de senha",
+		Corpo:        ru.corpo(usuario.Nome, token),
+	})
+}
+
+// ResetPassword troca a senha do dono do token, que deixa de valer após o uso. Todas as
+// sessões do usuário são encerradas e um eventual bloqueio por falhas de login é removido.
+func (ru *ResetSenhaUsecase) ResetPassword(token, novaSenha string) error {
+	reset, err := ru.repository.GetResetSenhaByHash(config.HashOpaqueToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil || reset.UsadoEm != nil || !reset.ExpiraEm.After(time.Now()) {
+		return ErrResetTokenInvalido
+	}
+
+	// Valida a nova senha antes de consumir o token para que o usuário possa tentar de novo
+	hash, err := ru.senhas.Hash(novaSenha)
+	if err != nil {
+		return err
+	}
+
+	used, err := ru.repository.MarkResetSenhaUsado(reset.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrResetTokenInvalido
+	}
+
+	usuario, err := ru.usuarioRepository.GetUsuarioById(reset.UsuarioId)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return ErrResetTokenInvalido
+	}
+
+	if err := ru.usuarioRepository.UpdateSenhaById(usuario.Id, hash); err != nil {
+		return err
+	}
+
+	if err := ru.tokens.RevokeUsuario(usuario.Id); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := ru.tentativas.Desbloquear(usuario.Login); err != nil {
+		fmt.Println(err)
+	}
+	return nil
+}
+
+// SetPassword troca a senha do usuário do login sem token, para administradores com acesso ao
+// servidor. Como em ResetPassword, as sessões do usuário são encerradas e o bloqueio é removido.
+func (ru *ResetSenhaUsecase) SetPassword(login, novaSenha string) error {
+	usuario, err := ru.usuarioRepository.GetUsuarioByLogin(login)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return ErrUsuarioInexistente
+	}
+
+	hash, err := ru.senhas.Hash(novaSenha)
+	if err != nil {
+		return err
+	}
+	if err := ru.usuarioRepository.UpdateSenhaById(usuario.Id, hash); err != nil {
+		return err
+	}
+
+	if err := ru.tokens.RevokeUsuario(usuario.Id); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := ru.tentativas.Desbloquear(usuario.Login); err != nil {
+		fmt.Println(err)
+	}
+	return nil
+}
+
+func (ru *ResetSenhaUsecase) DeleteExpired() error {
+	_, err := ru.repository.DeleteExpiredResetSenhas(time.Now())
+	return err
+}
+
+// RunCleanup executa DeleteExpired periodicamente até o contexto ser cancelado
+func (ru *ResetSenhaUsecase) RunCleanup(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, ru.DeleteExpired)
+}
+
+func (ru *ResetSenhaUsecase) corpo(nome, token string) string {
+	instrucao := "Use o token abaixo em /auth/reset-password para definir uma nova senha:\n\n" + token
+	if ru.config.URL != "" {
+		instrucao = "Acesse o link abaixo para definir uma nova senha:\n\n" + ru.config.URL + "?token=" + url.QueryEscape(token)
+	}
+
+	return fmt.Sprintf("Olá, %s.\n\nRecebemos um pedido de redefinição da sua senha. %s\n\nO token expira em %s e só pode ser usado uma vez. Se você não fez este pedido, ignore esta mensagem.",
+		nome, instrucao, ru.config.Duracao.Duration)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-api/model"
+	"sync"
+	"time"
+)
+
+// Rotinas executa e acompanha as rotinas em segundo plano do servidor. Uma rotina que termina
+// antes do encerramento, ou que entra em pânico, deixa a instância fora de /readyz em vez de
+// derrubar o processo.
+type Rotinas struct {
+	wg sync.WaitGroup
+
+	mu      sync.Mutex
+	rotinas []*model.RotinaStatus
+}
+
+func NewRotinas() *Rotinas {
+	return &Rotinas{}
+}
+
+// Run executa fn em uma goroutine até ctx ser cancelado
+func (r *Rotinas) Run(ctx context.Context, nome string, fn func(ctx context.Context)) {
+	status := &model.RotinaStatus{Nome: nome, Status: model.RotinaExecutando, IniciadaEm: time.Now()}
+	r.mu.Lock()
+	r.rotinas = append(r.rotinas, status)
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			recuperado := recover()
+			if recuperado != nil {
+				fmt.Printf("Rotina %s falhou: %v\n", nome, recuperado)
+			}
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			status.Status = model.RotinaEncerrada
+			if recuperado != nil {
+				status.Status = model.RotinaFalhou
+				status.Erro = fmt.Sprint(recuperado)
+			}
+		}()
+		fn(ctx)
+	}()
+}
+
+// Wait espera todas as rotinas terminarem
+func (r *Rotinas) Wait() {
+	r.wg.Wait()
+}
+
+// Status retorna a situação de cada rotina, na ordem em que foram iniciadas
+func (r *Rotinas) Status() []model.RotinaStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := make([]model.RotinaStatus, len(r.rotinas))
+	for i, rotina := range r.rotinas {
+		status[i] = *rotina
+	}
+	return status
+}
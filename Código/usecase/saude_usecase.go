@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-api/migrations"
+	"go-api/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SaudeUsecase verifica se a instância está pronta para receber requisições: se o banco
+// responde, se o esquema está na versão esperada e se as rotinas em segundo plano estão de pé
+type SaudeUsecase struct {
+	db       *sql.DB
+	migrator *migrations.Migrator
+	rotinas  *Rotinas
+	timeout  time.Duration
+
+	mu             sync.Mutex
+	ultimaSituacao string
+}
+
+func NewSaudeUsecase(db *sql.DB, migrator *migrations.Migrator, rotinas *Rotinas, timeout time.Duration) *SaudeUsecase {
+	return &SaudeUsecase{
+		db:       db,
+		migrator: migrator,
+		rotinas:  rotinas,
+		timeout:  timeout,
+	}
+}
+
+// Prontidao retorna o resumo do diagnóstico, que pode ser exposto sem autenticação. Quando a
+// instância deixa de estar pronta, o diagnóstico completo vai para o log.
+func (su *SaudeUsecase) Prontidao(ctx context.Context) model.Prontidao {
+	diagnostico := su.Diagnostico(ctx)
+	su.registra(diagnostico)
+	return diagnostico.Prontidao()
+}
+
+// registra escreve no log só as mudanças na situação dos componentes: durante uma queda o
+// balanceador de carga consulta /readyz a cada poucos segundos
+func (su *SaudeUsecase) registra(diagnostico model.Diagnostico) {
+	situacao := []string{"banco=" + diagnostico.Banco.Status, "migrations=" + diagnostico.Migrations.Status}
+	for _, rotina := range diagnostico.Rotinas {
+		situacao = append(situacao, rotina.Nome+"="+rotina.Status)
+	}
+	atual := strings.Join(situacao, ",")
+
+	su.mu.Lock()
+	anterior := su.ultimaSituacao
+	su.ultimaSituacao = atual
+	su.mu.Unlock()
+
+	switch {
+	case atual == anterior:
+	case diagnostico.Status != model.SaudeOk:
+		detalhes, _ := json.Marshal(diagnostico)
+		fmt.Printf("Instância indisponível: %s\n", detalhes)
+	case anterior != "":
+		fmt.Println("Instância pronta novamente")
+	}
+}
+
+// Diagnostico consulta o banco com o prazo configurado, para que um banco travado não prenda
+// a verificação do balanceador de carga
+func (su *SaudeUsecase) Diagnostico(ctx context.Context) model.Diagnostico {
+	ctx, cancel := context.WithTimeout(ctx, su.timeout)
+	defer cancel()
+
+	diagnostico := model.Diagnostico{
+		Status:     model.SaudeOk,
+		Banco:      su.banco(ctx),
+		Migrations: su.migrations(ctx),
+		Rotinas:    su.rotinas.Status(),
+	}
+	if diagnostico.Banco.Status != model.SaudeOk || diagnostico.Migrations.Status != model.SaudeOk {
+		diagnostico.Status = model.SaudeIndisponivel
+	}
+	for _, rotina := range diagnostico.Rotinas {
+		if rotina.Status != model.RotinaExecutando {
+			diagnostico.Status = model.SaudeIndisponivel
+		}
+	}
+	return diagnostico
+}
+
+func (su *SaudeUsecase) banco(ctx context.Context) model.BancoStatus {
+	inicio := time.Now()
+	err := su.db.PingContext(ctx)
+	stats := su.db.Stats()
+
+	status := model.BancoStatus{
+		Status:     model.SaudeOk,
+		LatenciaMs: time.Since(inicio).Milliseconds(),
+		Pool: model.PoolStatus{
+			MaxAbertas:    stats.MaxOpenConnections,
+			Abertas:       stats.OpenConnections,
+			EmUso:         stats.InUse,
+			Ociosas:       stats.Idle,
+			Esperas:       stats.WaitCount,
+			TempoEsperaMs: stats.WaitDuration.Milliseconds(),
+		},
+	}
+	if err != nil {
+		status.Status = model.SaudeIndisponivel
+		status.Erro = err.Error()
+	}
+	return status
+}
+
+// migrations fica indisponível tanto com migrations pendentes quanto com um esquema mais novo
+// que esta versão da API, aplicado por outra instância
+func (su *SaudeUsecase) migrations(ctx context.Context) model.MigrationsStatus {
+	status := model.MigrationsStatus{Status: model.SaudeOk, VersaoEsperada: su.migrator.Versao()}
+
+	lista, err := su.migrator.Status(ctx)
+	if err != nil {
+		status.Status = model.SaudeIndisponivel
+		status.Erro = err.Error()
+		return status
+	}
+	for _, migration := range lista {
+		if migration.AplicadaEm == nil {
+			status.Pendentes = append(status.Pendentes, fmt.Sprintf("%04d_%s", migration.Versao, migration.Nome))
+		} else if migration.Versao > status.Versao {
+			status.Versao = migration.Versao
+		}
+	}
+
+	switch {
+	case status.Versao > status.VersaoEsperada:
+		status.Status = model.SaudeIndisponivel
+		status.Erro = migrations.ErrEsquemaMaisNovo.Error()
+	case len(status.Pendentes) > 0:
+		status.Status = model.SaudeIndisponivel
+		status.Erro = fmt.Sprintf("%d migrations pendentes", len(status.Pendentes))
+	}
+	return status
+}
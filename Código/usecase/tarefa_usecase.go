@@ -1,26 +1,49 @@
 package usecase
 
 import (
+	"database/sql"
+	"errors"
 	"go-api/model"
 	"go-api/repository"
+	"strconv"
+)
+
+var (
+	ErrResponsavelProibido    = errors.New("apenas administradores podem atribuir tarefas a outros usuários")
+	ErrResponsavelInexistente = errors.New("o usuário responsável não existe")
 )
 
 type TarefaUsecase struct {
-	repository repository.TarefaRepository
+	repository repository.TarefaStore
 }
 
-func NewTarefaUseCase(repo repository.TarefaRepository) TarefaUsecase {
+func NewTarefaUseCase(repo repository.TarefaStore) TarefaUsecase {
 	return TarefaUsecase{
 		repository: repo,
 	}
 }
 
-func (tu *TarefaUsecase) GetTarefas() ([]model.Tarefa, error) {
+// GetTarefas retorna todas as tarefas para administradores e apenas as do próprio usuário para os demais
+func (tu *TarefaUsecase) GetTarefas(caller model.Caller) ([]model.Tarefa, error) {
+	if !caller.IsAdmin() {
+		return tu.repository.GetTarefasByUsuarioId(strconv.Itoa(caller.UsuarioId))
+	}
 	return tu.repository.GetTarefas()
 }
 
-func (tu *TarefaUsecase) CreateTarefa(tarefa model.Tarefa) (model.Tarefa, error) {
+// CreateTarefa usa quem fez a requisição como responsável quando nenhum é informado
+func (tu *TarefaUsecase) CreateTarefa(caller model.Caller, tarefa model.Tarefa) (model.Tarefa, error) {
+	if tarefa.UsuarioResp == "" {
+		tarefa.UsuarioResp = strconv.Itoa(caller.UsuarioId)
+	}
+	if !isResponsavel(caller, tarefa.UsuarioResp) {
+		return model.Tarefa{}, ErrResponsavelProibido
+	}
+
 	id, err := tu.repository.CreateTarefa(tarefa)
+	if errors.Is(err, repository.ErrReferenciaInvalida) {
+		return model.Tarefa{}, ErrResponsavelInexistente
+	}
 	if err != nil {
 		return model.Tarefa{}, err
 	}
@@ -29,22 +52,62 @@ func (tu *TarefaUsecase) CreateTarefa(tarefa model.Tarefa) (model.Tarefa, error)
 	return tarefa, nil
 }
 
-func (tu *TarefaUsecase) GetTarefaById(id_tarefa int) (*model.Tarefa, error) {
+// GetTarefaById retorna nil tanto para tarefas inexistentes quanto para tarefas de outro
+// usuário, para não revelar a existência de tarefas alheias
+func (tu *TarefaUsecase) GetTarefaById(caller model.Caller, id_tarefa int) (*model.Tarefa, error) {
 	tarefa, err := tu.repository.GetTarefaById(id_tarefa)
 	if err != nil {
 		return nil, err
 	}
+	if tarefa == nil || !isResponsavel(caller, tarefa.UsuarioResp) {
+		return nil, nil
+	}
 	return tarefa, nil
 }
 
-func (tu *TarefaUsecase) UpdateTarefaById(id_tarefa int, tarefa *model.Tarefa) error {
-	return tu.repository.UpdateTarefaById(id_tarefa, tarefa)
+func (tu *TarefaUsecase) UpdateTarefaById(caller model.Caller, id_tarefa int, tarefa *model.Tarefa) error {
+	atual, err := tu.GetTarefaById(caller, id_tarefa)
+	if err != nil {
+		return err
+	}
+	if atual == nil {
+		return sql.ErrNoRows
+	}
+
+	if tarefa.UsuarioResp == "" {
+		tarefa.UsuarioResp = atual.UsuarioResp
+	}
+	if !isResponsavel(caller, tarefa.UsuarioResp) {
+		return ErrResponsavelProibido
+	}
+
+	err = tu.repository.UpdateTarefaById(id_tarefa, tarefa)
+	if errors.Is(err, repository.ErrReferenciaInvalida) {
+		return ErrResponsavelInexistente
+	}
+	return err
 }
 
-func (tu *TarefaUsecase) SoftDeleteTarefaById(id_tarefa int) error {
+func (tu *TarefaUsecase) SoftDeleteTarefaById(caller model.Caller, id_tarefa int) error {
+	atual, err := tu.GetTarefaById(caller, id_tarefa)
+	if err != nil {
+		return err
+	}
+	if atual == nil {
+		return sql.ErrNoRows
+	}
 	return tu.repository.SoftDeleteTarefaById(id_tarefa)
 }
 
-func (tu *TarefaUsecase) GetTarefasByUsuarioId(usuarioId string) ([]model.Tarefa, error) {
+// GetTarefasByUsuarioId retorna sql.ErrNoRows quando um usuário comum consulta as tarefas de outro
+func (tu *TarefaUsecase) GetTarefasByUsuarioId(caller model.Caller, usuarioId string) ([]model.Tarefa, error) {
+	if !isResponsavel(caller, usuarioId) {
+		return nil, sql.ErrNoRows
+	}
 	return tu.repository.GetTarefasByUsuarioId(usuarioId)
 }
+
+// isResponsavel indica se quem fez a requisição pode acessar as tarefas do usuário informado
+func isResponsavel(caller model.Caller, usuarioResp string) bool {
+	return caller.IsAdmin() || usuarioResp == strconv.Itoa(caller.UsuarioId)
+}
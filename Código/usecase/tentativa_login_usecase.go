@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"go-api/config"
+	"go-api/repository"
+	"strings"
+	"time"
+)
+
+// BloqueioError indica que o login ou o IP está temporariamente impedido de tentar novamente
+type BloqueioError struct {
+	RetryAfter time.Duration
+}
+
+func (e *BloqueioError) Error() string {
+	return fmt.Sprintf("muitas tentativas de login, tente novamente em %s", e.RetryAfter.Round(time.Second))
+}
+
+// TentativaLoginUsecase conta as falhas de login por login e por IP e aplica esperas
+// progressivas até o bloqueio temporário, conforme os limites da configuração
+type TentativaLoginUsecase struct {
+	repository repository.TentativaLoginRepository
+	config     config.LoginProtectionConfig
+}
+
+func NewTentativaLoginUsecase(repo repository.TentativaLoginRepository, cfg config.LoginProtectionConfig) *TentativaLoginUsecase {
+	return &TentativaLoginUsecase{repository: repo, config: cfg}
+}
+
+func chaveLogin(login string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+func chaveIP(ip string) string {
+	return "ip:" + ip
+}
+
+func chavesTentativa(login, ip string) []string {
+	chaves := []string{chaveLogin(login)}
+	if ip != "" {
+		chaves = append(chaves, chaveIP(ip))
+	}
+	return chaves
+}
+
+// Verificar retorna um *BloqueioError enquanto o login ou o IP estiver aguardando para tentar novamente
+func (tu *TentativaLoginUsecase) Verificar(login, ip string) error {
+	tentativas, err := tu.repository.GetTentativas(chavesTentativa(login, ip)...)
+	if err != nil {
+		return err
+	}
+
+	var retryAfter time.Duration
+	now := time.Now()
+	for _, tentativa := range tentativas {
+		if restante := tentativa.BloqueadoAte.Sub(now); restante > retryAfter {
+			retryAfter = restante
+		}
+	}
+	if retryAfter > 0 {
+		return &BloqueioError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RegistrarFalha contabiliza uma falha de login para o login e para o IP. A espera é calculada
+// a partir do total devolvido pelo banco, para que falhas simultâneas não escapem do bloqueio.
+func (tu *TentativaLoginUsecase) RegistrarFalha(login, ip string) error {
+	now := time.Now()
+	for _, chave := range chavesTentativa(login, ip) {
+		maxFalhas := tu.config.MaxFalhas
+		if strings.HasPrefix(chave, "ip:") {
+			maxFalhas = tu.config.IPMaxFalhas
+		}
+
+		falhas, err := tu.repository.IncrementaFalhas(chave, now, now.Add(-config.LoginJanelaFalhas))
+		if err != nil {
+			return err
+		}
+
+		if falhas == maxFalhas {
+			fmt.Println("Bloqueando", chave, "após", falhas, "falhas de login")
+		}
+		if atraso := tu.config.Atraso(falhas, maxFalhas); atraso > 0 {
+			if err := tu.repository.ProrrogaBloqueio(chave, now.Add(atraso)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RegistrarSucesso zera as falhas do login. As do IP são mantidas para que um atacante
+// não consiga zerá-las entrando com a própria conta.
+func (tu *TentativaLoginUsecase) RegistrarSucesso(login string) error {
+	_, err := tu.repository.DeleteTentativa(chaveLogin(login))
+	return err
+}
+
+// Desbloquear remove as falhas e o bloqueio do login. Retorna false se o login não tinha falhas registradas.
+func (tu *TentativaLoginUsecase) Desbloquear(login string) (bool, error) {
+	return tu.repository.DeleteTentativa(chaveLogin(login))
+}
+
+func (tu *TentativaLoginUsecase) DeleteExpired() error {
+	now := time.Now()
+	_, err := tu.repository.DeleteTentativasExpiradas(now, now.Add(-config.LoginJanelaFalhas))
+	return err
+}
+
+// RunCleanup executa DeleteExpired periodicamente até o contexto ser cancelado
+func (tu *TentativaLoginUsecase) RunCleanup(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, tu.DeleteExpired)
+}
@@ -0,0 +1,314 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-api/config"
+	"go-api/model"
+	"go-api/repository"
+	"sync"
+	"time"
+)
+
+var ErrRefreshTokenInvalido = errors.New("refresh token inválido ou expirado")
+
+// Intervalo mínimo entre gravações do último uso de uma sessão
+const sessaoUltimoUsoIntervalo = time.Minute
+
+// Tempo que uma sessão sem refresh token válido é mantida antes de ser removida na limpeza. A
+// folga preserva a sessão recém-criada, cujo refresh token é gravado logo em seguida.
+const sessaoRetencao = time.Hour
+
+// Tamanho máximo do user-agent gravado na sessão
+const dispositivoMaxLen = 255
+
+// TokenUsecase emite os pares de token de acesso e refresh token, registra as sessões e
+// mantém a lista de tokens revogados. O banco é a fonte da verdade e o cache em memória
+// evita consultas repetidas para tokens já sabidamente revogados.
+type TokenUsecase struct {
+	repository        repository.TokenRepository
+	refreshRepository repository.RefreshTokenRepository
+	sessaoRepository  repository.SessaoRepository
+	config            config.JWTConfig
+	keys              *config.KeySet
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+func NewTokenUseCase(repo repository.TokenRepository, refreshRepo repository.RefreshTokenRepository, sessaoRepo repository.SessaoRepository, cfg config.JWTConfig, keys *config.KeySet) *TokenUsecase {
+	return &TokenUsecase{
+		repository:        repo,
+		refreshRepository: refreshRepo,
+		sessaoRepository:  sessaoRepo,
+		config:            cfg,
+		keys:              keys,
+		revoked:           make(map[string]time.Time),
+	}
+}
+
+// ParseToken valida um token de acesso com as chaves da API, sem consultar as revogações
+func (tu *TokenUsecase) ParseToken(token string) (*config.Claims, error) {
+	return tu.keys.ParseToken(token)
+}
+
+// GenerateChallengeToken emite o token de desafio trocado pelos tokens definitivos depois do segundo fator
+func (tu *TokenUsecase) GenerateChallengeToken(userId int) (string, error) {
+	return tu.keys.GenerateChallengeToken(userId)
+}
+
+func (tu *TokenUsecase) ParseChallengeToken(token string) (*config.Claims, error) {
+	return tu.keys.ParseChallengeToken(token)
+}
+
+// PublicJWKS retorna as chaves públicas usadas para assinar os tokens
+func (tu *TokenUsecase) PublicJWKS() config.JWKSet {
+	return tu.keys.PublicJWKS()
+}
+
+// StartSession registra uma nova sessão, que também é uma nova família de refresh tokens,
+// e emite o primeiro par de tokens dela
+func (tu *TokenUsecase) StartSession(usuario *model.Usuario, origem model.Origem) (*model.TokenResponse, error) {
+	id, err := config.RandomId()
+	if err != nil {
+		return nil, err
+	}
+
+	dispositivo := origem.UserAgent
+	if len(dispositivo) > dispositivoMaxLen {
+		dispositivo = dispositivo[:dispositivoMaxLen]
+	}
+
+	now := time.Now()
+	err = tu.sessaoRepository.CreateSessao(model.Sessao{
+		Id:          id,
+		UsuarioId:   usuario.Id,
+		Dispositivo: dispositivo,
+		IP:          origem.IP,
+		CriadaEm:    now,
+		UltimoUso:   now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tu.IssueTokens(usuario, id)
+}
+
+// IssueTokens emite um token de acesso e um novo refresh token para a sessão (família) informada
+func (tu *TokenUsecase) IssueTokens(usuario *model.Usuario, familia string) (*model.TokenResponse, error) {
+	accessToken, err := tu.keys.GenerateToken(usuario.Id, usuario.Papel, familia, tu.config.AccessTokenDuration.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := config.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tu.refreshRepository.CreateRefreshToken(model.RefreshToken{
+		TokenHash: config.HashOpaqueToken(refreshToken),
+		UsuarioId: usuario.Id,
+		Familia:   familia,
+		ExpiraEm:  time.Now().Add(tu.config.RefreshTokenDuration.Duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(tu.config.AccessTokenDuration.Seconds()),
+	}, nil
+}
+
+// ConsumeRefreshToken marca o refresh token como usado e o retorna para que um novo par seja
+// emitido na mesma família. Um refresh token já usado indica que ele vazou: toda a família é
+// revogada, derrubando tanto o atacante quanto o usuário legítimo, que precisará fazer login novamente.
+func (tu *TokenUsecase) ConsumeRefreshToken(refreshToken string) (*model.RefreshToken, error) {
+	stored, err := tu.refreshRepository.GetRefreshTokenByHash(config.HashOpaqueToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.Revogado == "S" || !stored.ExpiraEm.After(time.Now()) {
+		return nil, ErrRefreshTokenInvalido
+	}
+
+	if stored.UsadoEm != nil {
+		return nil, tu.revokeReusedFamilia(stored)
+	}
+
+	used, err := tu.refreshRepository.MarkRefreshTokenUsed(stored.Id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		// Outra requisição consumiu o mesmo token ao mesmo tempo
+		return nil, tu.revokeReusedFamilia(stored)
+	}
+
+	return stored, nil
+}
+
+// RevokeFamilia encerra a sessão: os refresh tokens dela são revogados e os tokens de
+// acesso passam a ser recusados em SessaoAtiva
+func (tu *TokenUsecase) RevokeFamilia(familia string) error {
+	if err := tu.refreshRepository.RevokeFamilia(familia); err != nil {
+		return err
+	}
+	return tu.sessaoRepository.EncerrarSessao(familia, time.Now())
+}
+
+// RevokeUsuario encerra todas as sessões do usuário
+func (tu *TokenUsecase) RevokeUsuario(usuarioId int) error {
+	if err := tu.refreshRepository.RevokeUsuario(usuarioId); err != nil {
+		return err
+	}
+	return tu.sessaoRepository.EncerrarSessoesDoUsuario(usuarioId, time.Now())
+}
+
+// RevokeOutrasSessoes encerra todas as sessões do usuário, exceto a da família informada
+func (tu *TokenUsecase) RevokeOutrasSessoes(usuarioId int, familia string) error {
+	if familia == "" {
+		return tu.RevokeUsuario(usuarioId)
+	}
+	if err := tu.refreshRepository.RevokeOutrasFamilias(usuarioId, familia); err != nil {
+		return err
+	}
+	return tu.sessaoRepository.EncerrarOutrasSessoes(usuarioId, familia, time.Now())
+}
+
+// SessaoAtiva indica se a sessão do token ainda não foi encerrada, registrando o último uso.
+// Sessões que não existem mais, por terem sido removidas na limpeza, também são recusadas.
+func (tu *TokenUsecase) SessaoAtiva(id string) (bool, error) {
+	sessao, err := tu.sessaoRepository.GetSessao(id)
+	if err != nil {
+		return false, err
+	}
+	if sessao == nil || sessao.EncerradaEm != nil {
+		return false, nil
+	}
+
+	if now := time.Now(); now.Sub(sessao.UltimoUso) >= sessaoUltimoUsoIntervalo {
+		if err := tu.sessaoRepository.TouchSessao(id, now); err != nil {
+			fmt.Println(err)
+		}
+	}
+	return true, nil
+}
+
+// GetSessoes lista as sessões ativas do usuário, marcando a sessão atual
+func (tu *TokenUsecase) GetSessoes(usuarioId int, atual string) ([]model.Sessao, error) {
+	sessoes, err := tu.sessaoRepository.GetSessoesAtivas(usuarioId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessoes {
+		sessoes[i].Atual = sessoes[i].Id == atual
+	}
+	return sessoes, nil
+}
+
+// EncerrarSessao encerra uma sessão do próprio usuário. Retorna sql.ErrNoRows para sessões
+// inexistentes, já encerradas ou de outro usuário.
+func (tu *TokenUsecase) EncerrarSessao(usuarioId int, id string) error {
+	sessao, err := tu.sessaoRepository.GetSessao(id)
+	if err != nil {
+		return err
+	}
+	if sessao == nil || sessao.EncerradaEm != nil || sessao.UsuarioId != usuarioId {
+		return sql.ErrNoRows
+	}
+	return tu.RevokeFamilia(id)
+}
+
+func (tu *TokenUsecase) revokeReusedFamilia(stored *model.RefreshToken) error {
+	fmt.Println("Reuso de refresh token detectado, revogando a família", stored.Familia, "do usuário", stored.UsuarioId)
+	if err := tu.RevokeFamilia(stored.Familia); err != nil {
+		return err
+	}
+	return ErrRefreshTokenInvalido
+}
+
+// LoadRevokedTokens preenche o cache com as revogações que ainda não expiraram
+func (tu *TokenUsecase) LoadRevokedTokens() error {
+	tokens, err := tu.repository.GetRevokedTokens(time.Now())
+	if err != nil {
+		return err
+	}
+
+	tu.mu.Lock()
+	defer tu.mu.Unlock()
+	for jti, expiraEm := range tokens {
+		tu.revoked[jti] = expiraEm
+	}
+	return nil
+}
+
+func (tu *TokenUsecase) RevokeToken(claims *config.Claims) error {
+	expiraEm := claims.ExpiresAt.Time
+	if err := tu.repository.RevokeToken(claims.ID, expiraEm); err != nil {
+		return err
+	}
+
+	tu.mu.Lock()
+	tu.revoked[claims.ID] = expiraEm
+	tu.mu.Unlock()
+	return nil
+}
+
+func (tu *TokenUsecase) IsTokenRevoked(claims *config.Claims) (bool, error) {
+	tu.mu.RLock()
+	_, cached := tu.revoked[claims.ID]
+	tu.mu.RUnlock()
+	if cached {
+		return true, nil
+	}
+
+	// Outra instância pode ter revogado o token, por isso o banco também é consultado
+	revoked, err := tu.repository.IsTokenRevoked(claims.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if revoked {
+		tu.mu.Lock()
+		tu.revoked[claims.ID] = claims.ExpiresAt.Time
+		tu.mu.Unlock()
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredTokens remove do banco e do cache as revogações de tokens que já expiraram,
+// pois esses tokens seriam recusados de qualquer forma, além dos refresh tokens expirados,
+// das sessões encerradas e das abandonadas, cujo último refresh token expirou há mais de sessaoRetencao
+func (tu *TokenUsecase) DeleteExpiredTokens() error {
+	now := time.Now()
+
+	tu.mu.Lock()
+	for jti, expiraEm := range tu.revoked {
+		if !expiraEm.After(now) {
+			delete(tu.revoked, jti)
+		}
+	}
+	tu.mu.Unlock()
+
+	if _, err := tu.repository.DeleteExpiredTokens(now); err != nil {
+		return err
+	}
+	if _, err := tu.refreshRepository.DeleteExpiredRefreshTokens(now); err != nil {
+		return err
+	}
+	_, err := tu.sessaoRepository.DeleteSessoesEncerradas(now.Add(-sessaoRetencao))
+	return err
+}
+
+// RunCleanup executa DeleteExpiredTokens periodicamente até o contexto ser cancelado
+func (tu *TokenUsecase) RunCleanup(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, tu.DeleteExpiredTokens)
+}
@@ -1,17 +1,31 @@
 package usecase
 
 import (
+	"database/sql"
+	"errors"
+	"go-api/config"
 	"go-api/model"
 	"go-api/repository"
 )
 
+var (
+	ErrPapelInvalido      = errors.New("papel inválido, use admin ou membro")
+	ErrStatusInvalido     = errors.New("status inválido, use pendente, ativo, suspenso ou desativado")
+	ErrTransicaoInvalida  = errors.New("a conta não pode passar do status atual para o status informado")
+	ErrUsuarioInexistente = errors.New("usuário não encontrado")
+)
+
 type UsuarioUsecase struct {
-	repository repository.UsuarioRepository
+	repository repository.UsuarioStore
+	tokens     *TokenUsecase
+	senhas     config.PasswordConfig
 }
 
-func NewUsuarioUseCase(repo repository.UsuarioRepository) UsuarioUsecase {
+func NewUsuarioUseCase(repo repository.UsuarioStore, tokens *TokenUsecase, senhas config.PasswordConfig) UsuarioUsecase {
 	return UsuarioUsecase{
 		repository: repo,
+		tokens:     tokens,
+		senhas:     senhas,
 	}
 }
 
@@ -20,12 +34,36 @@ func (uu *UsuarioUsecase) GetUsuarios() ([]model.Usuario, error) {
 }
 
 func (uu *UsuarioUsecase) CreateUsuario(usuario model.Usuario) (model.Usuario, error) {
+	if usuario.Papel == "" {
+		usuario.Papel = model.PapelMembro
+	}
+	if !model.IsPapelValido(usuario.Papel) {
+		return model.Usuario{}, ErrPapelInvalido
+	}
+	// Contas nascem ativas ou pendentes de ativação por um administrador
+	if usuario.Status == "" {
+		usuario.Status = model.StatusAtivo
+	}
+	if usuario.Status != model.StatusAtivo && usuario.Status != model.StatusPendente {
+		return model.Usuario{}, ErrStatusInvalido
+	}
+
+	hash, err := uu.senhas.Hash(usuario.Senha)
+	if err != nil {
+		return model.Usuario{}, err
+	}
+	usuario.Senha = hash
+
 	id, err := uu.repository.CreateUsuario(usuario)
+	if errors.Is(err, repository.ErrRegistroDuplicado) {
+		return model.Usuario{}, ErrLoginEmUso
+	}
 	if err != nil {
 		return model.Usuario{}, err
 	}
 
 	usuario.Id = id
+	usuario.Senha = ""
 
 	return usuario, nil
 }
@@ -39,14 +77,77 @@ func (uu *UsuarioUsecase) GetUsuarioById(id_usuario int) (*model.Usuario, error)
 	return usuario, nil
 }
 
+// UpdateUsuarioById altera o nome e o login. A senha não muda por aqui: a troca passa por
+// AuthUsecase.ChangePassword, que exige a senha atual e encerra as outras sessões.
 func (uu *UsuarioUsecase) UpdateUsuarioById(id_usuario int, usuario *model.Usuario) error {
-	err := uu.repository.UpdateUsuarioById(id_usuario, usuario)
+	err := uu.repository.UpdatePerfilById(id_usuario, usuario.Nome, usuario.Login)
+	if errors.Is(err, repository.ErrRegistroDuplicado) {
+		return ErrLoginEmUso
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// UpdatePapelById altera o papel do usuário e encerra todas as suas sessões. O papel viaja nas
+// claims do token de acesso, então os tokens já emitidos deixariam o papel antigo valer até expirar.
+func (uu *UsuarioUsecase) UpdatePapelById(id_usuario int, papel string) error {
+	if !model.IsPapelValido(papel) {
+		return ErrPapelInvalido
+	}
+
+	usuario, err := uu.repository.GetUsuarioById(id_usuario)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return sql.ErrNoRows
+	}
+	if usuario.Papel == papel {
+		return nil
+	}
+
+	if err := uu.repository.UpdatePapelById(id_usuario, papel); err != nil {
+		return err
+	}
+	return uu.tokens.RevokeUsuario(id_usuario)
+}
+
+// UpdateStatusById move a conta pelo ciclo de vida. Ao sair do estado ativo, todas as sessões
+// do usuário são encerradas, de modo que os tokens já emitidos deixam de valer imediatamente.
+func (uu *UsuarioUsecase) UpdateStatusById(id_usuario int, status string) error {
+	if !model.IsStatusValido(status) {
+		return ErrStatusInvalido
+	}
+
+	usuario, err := uu.repository.GetUsuarioById(id_usuario)
+	if err != nil {
+		return err
+	}
+	if usuario == nil {
+		return sql.ErrNoRows
+	}
+	if usuario.Status == status {
+		return nil
+	}
+	if !model.IsTransicaoValida(usuario.Status, status) {
+		return ErrTransicaoInvalida
+	}
+
+	if err := uu.repository.UpdateStatusById(id_usuario, status); err != nil {
+		return err
+	}
+	if status == model.StatusAtivo {
+		return nil
+	}
+	return uu.tokens.RevokeUsuario(id_usuario)
+}
+
+// SoftDeleteUsuarioById desativa o usuário e encerra todas as suas sessões
 func (uu *UsuarioUsecase) SoftDeleteUsuarioById(id_usuario int) error {
-	return uu.repository.SoftDeleteUsuarioById(id_usuario)
+	if err := uu.repository.SoftDeleteUsuarioById(id_usuario); err != nil {
+		return err
+	}
+	return uu.tokens.RevokeUsuario(id_usuario)
 }